@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// commands anyone can use
+const rolePublic = ""
+
+// the role each command requires. commands missing here are refused, so a
+// new command has to choose its role. "" stands for messages that are not
+// commands, the answers to forms and imports
+var commandRoles = map[string]string{
+	"":               rolePublic,
+	"help":           rolePublic,
+	"group":          rolePublic,
+	"subjects":       rolePublic,
+	"today":          rolePublic,
+	"tomorrow":       rolePublic,
+	"thisweek":       rolePublic,
+	"nextweek":       rolePublic,
+	"week":           rolePublic,
+	"weeks":          rolePublic,
+	"whichweek":      rolePublic,
+	"now":            rolePublic,
+	"next":           rolePublic,
+	"date":           rolePublic,
+	"day":            rolePublic,
+	"ics":            rolePublic,
+	"remind":         rolePublic,
+	"digest":         rolePublic,
+	"settings":       rolePublic,
+	"language":       rolePublic,
+	"notify":         rolePublic,
+	"calendar":       rolePublic,
+	"cancel":         mdb.RoleEditor,
+	"changeroom":     mdb.RoleEditor,
+	"changelecturer": mdb.RoleEditor,
+	"addoneoff":      mdb.RoleEditor,
+	"overrides":      mdb.RoleEditor,
+	"deleteoverride": mdb.RoleEditor,
+	"addsubject":     mdb.RoleAdmin,
+	"subjectname":    mdb.RoleAdmin,
+	"editsubject":    mdb.RoleAdmin,
+	"deletesubject":  mdb.RoleAdmin,
+	"addlecture":     mdb.RoleAdmin,
+	"editlecture":    mdb.RoleAdmin,
+	"deletelecture":  mdb.RoleAdmin,
+	"lectures":       mdb.RoleAdmin,
+	"history":        mdb.RoleAdmin,
+	"undo":           mdb.RoleAdmin,
+	"import":         mdb.RoleAdmin,
+	"apitoken":       mdb.RoleAdmin,
+	"export":         mdb.RoleAdmin,
+	"addgroup":       mdb.RoleOwner,
+	"setperiods":     mdb.RoleOwner,
+	"grant":          mdb.RoleOwner,
+	"revoke":         mdb.RoleOwner,
+}
+
+// the role commands that change the whole chat require in group chats
+var groupChatRoles = map[string]string{
+	"language": mdb.RoleAdmin,
+}
+
+// the role undoing the entry requires, the one of the commands that make such
+// changes. changes of the whole bot, the catalog lists, groups and roles,
+// belong to the owners
+func undoRole(e mdb.AuditEntry) string {
+	switch e.Kind() {
+	case "lecture", "subject", "override":
+		if e.Group != "" {
+			return mdb.RoleAdmin
+		}
+	}
+	return mdb.RoleOwner
+}
+
+// reports whether the user holds the role, or a more powerful one, in the
+// group
+func HasRole(db mdb.RoleStore, group string, userID int64, role string) bool {
+	if role == rolePublic {
+		return true
+	}
+	held, err := mdb.UserRole(db, userID, group)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return false
+	}
+	return mdb.RoleRank(held) >= mdb.RoleRank(role)
+}
+
+// lets the command through when the user holds the role it requires in the
+// group. commands of other roles and unknown commands are ignored, as if the
+// bot did not know them
+func Authorize(db mdb.RoleStore, group string, chatID, userID int64, command string) bool {
+	role, ok := commandRoles[command]
+	if !ok {
+		log.Printf("user %d sent unknown command /%v", userID, command)
+		return false
+	}
+	if chatRole, ok := groupChatRoles[command]; ok && chatID != userID {
+		role = chatRole
+	}
+	if HasRole(db, group, userID, role) {
+		return true
+	}
+	log.Printf("user %d lacks role %v for /%v in group %v", userID, role, command, group)
+	return false
+}
+
+// makes the users of TIMETABLE_ADMINS_USERID owners when nobody owns the bot
+// yet, and turns the admins stored in the groups into grants. the changes
+// are recorded in the audit log as made by user 0
+func prepareRoles(db mdb.Store, env string) error {
+	db = mdb.Audit(db, 0)
+	var owners []int64
+	for _, field := range strings.Split(env, "|") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		userID, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing TIMETABLE_ADMINS_USERID: %w", err)
+		}
+		owners = append(owners, userID)
+	}
+	if err := mdb.SeedOwners(db, owners); err != nil {
+		return err
+	}
+	return mdb.MigrateGroupAdmins(db)
+}
+
+// handles "/grant", the roles of the group, and "/grant <userID> <role>
+// [group]". admins and editors are granted in the group of the chat unless
+// another one is given, owners in every group
+func HandleGrant(db mdb.Store, chatID int64, userID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		sendGrants(db, chatID, group, lang, bot)
+		return
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "grant.usage")))
+		return
+	}
+	target, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "grant.usage")))
+		return
+	}
+	role := strings.ToLower(fields[1])
+	if len(fields) == 3 {
+		group = fields[2]
+	}
+	if role == mdb.RoleOwner {
+		group = ""
+	} else if _, err := db.GetGroup(group); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	g := mdb.Grant{UserID: target, Group: group, Role: role, GrantedBy: userID, Time: time.Now()}
+	if err := db.SaveGrant(g); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	log.Printf("user %d granted %v to %d in group %q", userID, role, target, group)
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "grant.done", role, target, grantScope(group, lang))))
+}
+
+// handles "/revoke <userID> [group]", every role of the user or the one in
+// the group. the last owner can't be revoked
+func HandleRevoke(db mdb.Store, chatID int64, userID int64, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	var target int64
+	var err error
+	if len(fields) > 0 {
+		target, err = strconv.ParseInt(fields[0], 10, 64)
+	}
+	if len(fields) == 0 || len(fields) > 2 || err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "revoke.usage")))
+		return
+	}
+	filter := bson.M{"user_id": target}
+	if len(fields) == 2 {
+		filter["group"] = fields[1]
+	}
+	grants, err := db.GetGrants(filter)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	if len(grants) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "revoke.none", target)))
+		return
+	}
+	var revoked []string
+	for _, g := range grants {
+		err := mdb.RevokeGrant(db, g.UserID, g.Group)
+		if errors.Is(err, mdb.ErrLastOwner) {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "revoke.last_owner")))
+			continue
+		}
+		if err != nil && !errors.Is(err, mdb.ErrNotFound) {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+			return
+		}
+		log.Printf("user %d revoked %v of %d in group %q", userID, g.Role, target, g.Group)
+		revoked = append(revoked, g.Role+grantScope(g.Group, lang))
+	}
+	if len(revoked) > 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "revoke.done", target, strings.Join(revoked, ", "))))
+	}
+}
+
+// lists the owners and the roles granted in the group
+func sendGrants(db mdb.RoleStore, chatID int64, group string, lang string, bot *tgbotapi.BotAPI) {
+	grants, err := db.GetGrants(bson.M{"group": bson.M{"$in": []string{"", group}}})
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	text := T(lang, "grant.list", group) + "\n\n"
+	for _, g := range grants {
+		text += fmt.Sprintf("`%d` - %v%v\n", g.UserID, g.Role, grantScope(g.Group, lang))
+	}
+	text += "\n" + T(lang, "grant.usage") + "\n" + T(lang, "revoke.usage")
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	bot.Send(msg)
+}
+
+// where a grant applies
+func grantScope(group string, lang string) string {
+	if group == "" {
+		return T(lang, "grant.everywhere")
+	}
+	return T(lang, "grant.group", group)
+}
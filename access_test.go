@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+func TestAuthorize(t *testing.T) {
+	db := mdb.NewMemDb()
+	for _, g := range []mdb.Grant{
+		{UserID: 1, Role: mdb.RoleOwner},
+		{UserID: 2, Group: "a", Role: mdb.RoleAdmin},
+		{UserID: 3, Group: "a", Role: mdb.RoleEditor},
+	} {
+		if err := db.SaveGrant(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		userID  int64
+		chatID  int64
+		group   string
+		command string
+		want    bool
+	}{
+		{4, 4, "a", "today", true},
+		{4, 4, "a", "", true},
+		{4, 4, "a", "nosuchcommand", false},
+		{1, 1, "a", "nosuchcommand", false},
+		{3, 3, "a", "cancel", true},
+		{3, 3, "a", "addlecture", false},
+		{2, 2, "a", "addlecture", true},
+		{2, 2, "b", "addlecture", false},
+		{2, 2, "a", "grant", false},
+		{1, 1, "b", "grant", true},
+		{4, 4, "a", "language", true},
+		{4, -100, "a", "language", false},
+		{2, -100, "a", "language", true},
+	}
+	for _, tt := range tests {
+		if got := Authorize(db, tt.group, tt.chatID, tt.userID, tt.command); got != tt.want {
+			t.Errorf("Authorize(user %d, chat %d, group %v, /%v) = %v, want %v", tt.userID, tt.chatID, tt.group, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestUndoRole(t *testing.T) {
+	lecture := &mdb.Lecture{Group: "a"}
+	tests := []struct {
+		name  string
+		entry mdb.AuditEntry
+		want  string
+	}{
+		{"lecture", mdb.AuditEntry{Group: "a", Action: mdb.AuditInsert, After: mdb.AuditDoc{Lecture: lecture}}, mdb.RoleAdmin},
+		{"deleted subject", mdb.AuditEntry{Group: "a", Action: mdb.AuditDelete, Before: mdb.AuditDoc{Subject: &mdb.Subject{}}}, mdb.RoleAdmin},
+		{"override", mdb.AuditEntry{Group: "a", Action: mdb.AuditInsert, After: mdb.AuditDoc{Override: &mdb.Override{}}}, mdb.RoleAdmin},
+		{"grant of the group", mdb.AuditEntry{Group: "a", Action: mdb.AuditInsert, After: mdb.AuditDoc{Grant: &mdb.Grant{}}}, mdb.RoleOwner},
+		{"owner grant", mdb.AuditEntry{Action: mdb.AuditDelete, Before: mdb.AuditDoc{Grant: &mdb.Grant{}}}, mdb.RoleOwner},
+		{"group", mdb.AuditEntry{Group: "a", Action: mdb.AuditUpdate, Before: mdb.AuditDoc{Group: &mdb.Group{}}, After: mdb.AuditDoc{Group: &mdb.Group{}}}, mdb.RoleOwner},
+		{"periods", mdb.AuditEntry{Action: mdb.AuditUpdate, After: mdb.AuditDoc{Periods: mdb.DefaultPeriods}}, mdb.RoleOwner},
+		{"days", mdb.AuditEntry{Action: mdb.AuditUpdate, After: mdb.AuditDoc{Days: mdb.DefaultDays}}, mdb.RoleOwner},
+		{"lecture without a group", mdb.AuditEntry{Action: mdb.AuditInsert, After: mdb.AuditDoc{Lecture: lecture}}, mdb.RoleOwner},
+	}
+	for _, tt := range tests {
+		if got := undoRole(tt.entry); got != tt.want {
+			t.Errorf("%v: undoRole = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// APILecture is a lecture as the http api returns it
+type APILecture struct {
+	ID          string `json:"id"`
+	Group       string `json:"group"`
+	Week        string `json:"week"`
+	Day         int    `json:"day"`
+	DayName     string `json:"day_name"`
+	Period      int    `json:"period"`
+	PeriodTime  string `json:"period_time"`
+	Subject     string `json:"subject"`
+	SubjectName string `json:"subject_name"`
+	Type        string `json:"type"`
+	Room        string `json:"room"`
+	Lecturer    string `json:"lecturer"`
+	SubGroup    string `json:"sub_group"`
+	Status      string `json:"status,omitempty"`
+}
+
+// APIDay is the timetable of one date as the http api returns it
+type APIDay struct {
+	Date     string       `json:"date"`
+	Week     int          `json:"week"`
+	Day      int          `json:"day"`
+	DayName  string       `json:"day_name"`
+	Lectures []APILecture `json:"lectures"`
+}
+
+func toAPILecture(l mdb.Lecture, cat mdb.Catalog) APILecture {
+	return APILecture{
+		ID:          l.ID.Hex(),
+		Group:       l.Group,
+		Week:        l.Week,
+		Day:         l.Day,
+		DayName:     cat.Days[l.Day],
+		Period:      l.Time,
+		PeriodTime:  cat.Periods[l.Time].String(),
+		Subject:     l.Subject,
+		SubjectName: cat.Subjects[l.Subject].Name,
+		Type:        l.Type,
+		Room:        l.Room,
+		Lecturer:    l.Lecturer,
+		SubGroup:    l.SubGroup,
+		Status:      string(l.Status),
+	}
+}
+
+func toAPIDay(day DaySchedule, cat mdb.Catalog) APIDay {
+	out := APIDay{
+		Date:     day.Date.Format(mdb.DateLayout),
+		Week:     day.Week,
+		Day:      day.Day,
+		DayName:  cat.Days[day.Day],
+		Lectures: []APILecture{},
+	}
+	for _, l := range day.Lectures {
+		out.Lectures = append(out.Lectures, toAPILecture(l, cat))
+	}
+	return out
+}
+
+// the language of the errors the api returns
+const apiLanguage = "en"
+
+// API serves the timetable as json
+type API struct {
+	db           mdb.Store
+	defaultGroup string
+}
+
+func (a *API) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/today", a.today)
+	mux.HandleFunc("GET /api/week", a.week)
+	mux.HandleFunc("GET /api/lectures", a.lectures)
+	mux.HandleFunc("POST /api/lectures", a.admin(a.createLecture))
+	mux.HandleFunc("PATCH /api/lectures/{id}", a.admin(a.updateLecture))
+	mux.HandleFunc("DELETE /api/lectures/{id}", a.admin(a.deleteLecture))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error writing response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// reads ?group= and ?subgroup= into the same options the bot commands use.
+// without a subgroup the lectures of every subgroup are returned
+func (a *API) args(r *http.Request) (mdb.Args, mdb.Catalog, error) {
+	opt := mdb.Args{StudyGroup: r.URL.Query().Get("group"), Group: r.URL.Query().Get("subgroup")}
+	if opt.StudyGroup == "" {
+		opt.StudyGroup = a.defaultGroup
+	}
+	if _, ok := mdb.SubGroup[opt.Group]; opt.Group != "" && !ok {
+		return opt, mdb.Catalog{}, fmt.Errorf("invalid subgroup %q", opt.Group)
+	}
+	if _, err := a.db.GetGroup(opt.StudyGroup); err != nil {
+		return opt, mdb.Catalog{}, err
+	}
+	cat, err := mdb.GetCatalog(a.db, opt.StudyGroup)
+	return opt, cat, err
+}
+
+func statusOf(err error) int {
+	if errors.Is(err, mdb.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	var conflict *mdb.ConflictError
+	if errors.As(err, &conflict) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// the status of an error saving a lecture, clashes are the client's
+func storeStatus(err error) int {
+	var conflict *mdb.ConflictError
+	if errors.As(err, &conflict) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// whether the request saves a lecture clashing with stored ones anyway
+func forced(r *http.Request) bool {
+	return r.URL.Query().Get("force") == "true"
+}
+
+// GET /api/today
+func (a *API) today(w http.ResponseWriter, r *http.Request) {
+	opt, cat, err := a.args(r)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	day, err := GetDaySchedule(a.db, opt, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAPIDay(day, cat))
+}
+
+// GET /api/week?n= where n counts weeks from the current one, 1 is next week
+func (a *API) week(w http.ResponseWriter, r *http.Request) {
+	opt, cat, err := a.args(r)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	n := 0
+	if str := r.URL.Query().Get("n"); str != "" {
+		if n, err = strconv.Atoi(str); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid n %q", str))
+			return
+		}
+	}
+	days, err := GetWeekSchedule(a.db, opt, WeekStart(time.Now()).AddDate(0, 0, 7*n))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]APIDay, len(days))
+	for i, day := range days {
+		out[i] = toAPIDay(day, cat)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /api/lectures?week=&day=&subgroup= lists the recurring lectures
+func (a *API) lectures(w http.ResponseWriter, r *http.Request) {
+	opt, cat, err := a.args(r)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	query := mdb.LectureQuery{Group: opt.StudyGroup, SubGroup: opt.Group}
+	for name, target := range map[string]*int{"week": &query.Week, "day": &query.Day} {
+		if str := r.URL.Query().Get(name); str != "" {
+			if *target, err = strconv.Atoi(str); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %v %q", name, str))
+				return
+			}
+		}
+	}
+	lectures, err := a.db.GetLectures(query.Filter())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := []APILecture{}
+	for _, l := range lectures {
+		out = append(out, toAPILecture(l, cat))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// admin only lets requests with the api token of a group admin through
+func (a *API) admin(next func(w http.ResponseWriter, r *http.Request, t mdb.Token)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing bearer token"))
+			return
+		}
+		t, err := a.db.GetToken(strings.TrimSpace(token))
+		if err != nil || t.Kind != mdb.TokenAPI {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid token"))
+			return
+		}
+		if !HasRole(a.db, t.Group, t.UserID, mdb.RoleAdmin) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("not an admin of group %v", t.Group))
+			return
+		}
+		next(w, r, t)
+	}
+}
+
+// checks a row the way the importer does, inside the group of the token
+func (a *API) validate(row LectureRow, t mdb.Token) (mdb.Lecture, error) {
+	lectures, errs := NewImporter(a.db, t.Group, true, apiLanguage).Lectures([]LectureRow{row}, []int{1})
+	if len(errs) > 0 {
+		return mdb.Lecture{}, errs[0].Err
+	}
+	return lectures[0], nil
+}
+
+// the lecture with the id in the path, if it belongs to the group of the token
+func (a *API) lecture(r *http.Request, t mdb.Token) (mdb.Lecture, error) {
+	lecture, err := a.db.GetLecture(r.PathValue("id"))
+	if err != nil || lecture.Group != t.Group {
+		return mdb.Lecture{}, fmt.Errorf("lecture %v: %w", r.PathValue("id"), mdb.ErrNotFound)
+	}
+	return lecture, nil
+}
+
+// POST /api/lectures with a json row like the importer reads. lectures
+// clashing with stored ones need ?force=true
+func (a *API) createLecture(w http.ResponseWriter, r *http.Request, t mdb.Token) {
+	var row LectureRow
+	if err := json.NewDecoder(r.Body).Decode(&row); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	row.ID = ""
+	lecture, err := a.validate(row, t)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	lectures := []mdb.Lecture{lecture}
+	if err := mdb.ImportLecturesChecked(mdb.Audit(a.db, t.UserID), lectures, forced(r)); err != nil {
+		writeError(w, storeStatus(err), err)
+		return
+	}
+	log.Printf("api: user %v added lecture %v", t.UserID, lectures[0].ID.Hex())
+	cat, _ := mdb.GetCatalog(a.db, t.Group)
+	writeJSON(w, http.StatusCreated, toAPILecture(lectures[0], cat))
+}
+
+// PATCH /api/lectures/{id} with the fields of a row to change. changes
+// clashing with stored lectures need ?force=true
+func (a *API) updateLecture(w http.ResponseWriter, r *http.Request, t mdb.Token) {
+	old, err := a.lecture(r, t)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	var patch LectureRow
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	row := LectureToRow(old)
+	for _, column := range rowColumns {
+		if value := *patch.field(column); value != "" && column != "id" && column != "group" {
+			*row.field(column) = value
+		}
+	}
+	// a new subject brings its own lecturer unless one is given
+	if patch.Subject != "" && patch.Lecturer == "" {
+		row.Lecturer = ""
+	}
+	lecture, err := a.validate(row, t)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	lecture.ID = old.ID
+	if err := mdb.UpdateLectureChecked(mdb.Audit(a.db, t.UserID), old.ID, lecture, forced(r)); err != nil {
+		writeError(w, storeStatus(err), err)
+		return
+	}
+	log.Printf("api: user %v updated lecture %v", t.UserID, old.ID.Hex())
+	cat, _ := mdb.GetCatalog(a.db, t.Group)
+	writeJSON(w, http.StatusOK, toAPILecture(lecture, cat))
+}
+
+// DELETE /api/lectures/{id}
+func (a *API) deleteLecture(w http.ResponseWriter, r *http.Request, t mdb.Token) {
+	lecture, err := a.lecture(r, t)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	if err := mdb.Audit(a.db, t.UserID).DeleteLecture(lecture.ID.Hex()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	log.Printf("api: user %v deleted lecture %v", t.UserID, lecture.ID.Hex())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// issues an api token for the admin, or revokes it with "/apitoken revoke"
+func HandleAPIToken(db mdb.TokenStore, chatID int64, userID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	if chatID != userID {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "apitoken.private")))
+		return
+	}
+	count, err := db.DeleteUserTokens(userID, mdb.TokenAPI)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	if strings.TrimSpace(args) == "revoke" {
+		bot.Send(tgbotapi.NewMessage(chatID, Tn(lang, "apitoken.revoked", count)))
+		return
+	}
+	token, err := NewToken()
+	if err == nil {
+		err = db.InsertToken(mdb.Token{Token: token, Kind: mdb.TokenAPI, UserID: userID, Group: group, Created: time.Now()})
+	}
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	log.Printf("api token issued to %v", userID)
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "apitoken.issued", group, token)))
+}
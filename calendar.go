@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// generates a random token that is hard to guess
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// issues a calendar feed token for the user, replacing the previous one, or
+// revokes it with "/calendar revoke"
+func HandleCalendar(db mdb.TokenStore, chatID int64, userID int64, args string, opt mdb.Args, bot *tgbotapi.BotAPI) {
+	lang := opt.Lang
+	publicURL := strings.TrimSuffix(os.Getenv("TIMETABLE_PUBLIC_URL"), "/")
+	// the link is personal, so it must not be posted in group chats
+	if chatID != userID {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "calendar.private")))
+		return
+	}
+	if strings.TrimSpace(args) == "revoke" {
+		count, err := db.DeleteUserTokens(userID, mdb.TokenCalendar)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		if count == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "calendar.none")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "calendar.revoked")))
+		return
+	}
+	if publicURL == "" || os.Getenv("TIMETABLE_HTTP_ADDR") == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "calendar.unconfigured")))
+		return
+	}
+	token, err := NewToken()
+	if err == nil {
+		_, err = db.DeleteUserTokens(userID, mdb.TokenCalendar)
+	}
+	if err == nil {
+		err = db.InsertToken(mdb.Token{
+			Token:    token,
+			Kind:     mdb.TokenCalendar,
+			UserID:   userID,
+			Group:    opt.StudyGroup,
+			SubGroup: opt.Group,
+			Created:  time.Now(),
+		})
+	}
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	log.Printf("calendar token issued to %v", userID)
+	url := fmt.Sprintf("%v/calendar/%v.ics", publicURL, token)
+	msg := tgbotapi.NewMessage(chatID, T(lang, "calendar.link", url))
+	msg.DisableWebPagePreview = true
+	bot.Send(msg)
+}
+
+// serves /calendar/<token>.ics, built live from the store
+func CalendarHandler(db mdb.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		t, err := db.GetToken(token)
+		if errors.Is(err, mdb.ErrNotFound) || (err == nil && t.Kind != mdb.TokenCalendar) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			log.Printf("error: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		from, err := parseDate(os.Getenv("SEMESTER_START_DATE"))
+		if err != nil {
+			from = time.Now()
+		}
+		opt := mdb.Args{Group: t.SubGroup, StudyGroup: t.Group, Lang: UserPrefs(db, t.UserID).Language}
+		data, err := BuildICS(db, opt, from)
+		if err != nil {
+			log.Printf("error: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Write(data)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// routes a button press to its handler by the prefix of the callback data
+func HandleCallbackQuery(db mdb.Store, sessions *Sessions, query *tgbotapi.CallbackQuery, defaultGroup string, bot *tgbotapi.BotAPI) {
+	// buttons of inline messages have no message to edit
+	if query.Message == nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	group := ChatGroup(db, query.Message.Chat.ID, defaultGroup)
+	lang := Language(db, query.Message.Chat.ID, query.From.ID)
+	prefix, _, _ := strings.Cut(query.Data, ":")
+	switch prefix {
+	case "nav":
+		HandleNavCallback(db, query, group, lang, bot)
+	case "set":
+		HandleSettingsCallback(db, query, bot)
+	case "lec":
+		if !HasRole(db, group, query.From.ID, mdb.RoleAdmin) {
+			bot.Request(tgbotapi.NewCallback(query.ID, T(lang, "list.denied")))
+			return
+		}
+		HandleLecturesCallback(db, sessions, query, group, lang, bot)
+	default:
+		log.Printf("unknown callback data %q", query.Data)
+	}
+	// stops the spinner on the button, handlers that show a notice answer
+	// themselves first and this one is ignored
+	bot.Request(tgbotapi.NewCallback(query.ID, ""))
+}
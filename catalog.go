@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// splits "key | name | lecturer" into trimmed fields
+func splitFields(args string, n int) []string {
+	fields := strings.SplitN(args, "|", n)
+	for len(fields) < n {
+		fields = append(fields, "")
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// adds a subject from "/addsubject <key> | <name> | <lecturer>"
+func AddSubject(db mdb.CatalogStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := splitFields(args, 3)
+	subject := mdb.Subject{Key: fields[0], Name: fields[1], Lecturer: fields[2], Group: group}
+	if subject.Key == "" || subject.Name == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.add_usage")))
+		return
+	}
+	if err := db.InsertSubject(subject); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	log.Printf("New subject : %+v", subject)
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.added", subject.Key)))
+}
+
+// edits a subject from "/editsubject <key> | <name> | <lecturer>", empty
+// fields keep their old value. lectures that still have the old lecturer
+// are moved to the new one
+func EditSubject(db mdb.Store, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := splitFields(args, 3)
+	if fields[0] == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.edit_usage")))
+		return
+	}
+	subjects, err := db.GetSubjects(group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	old, ok := subjects[fields[0]]
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.notfound", fields[0])))
+		return
+	}
+	subject := old
+	if fields[1] != "" {
+		subject.Name = fields[1]
+	}
+	if fields[2] != "" {
+		subject.Lecturer = fields[2]
+	}
+	if err := db.UpdateSubject(subject); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	moved := 0
+	if subject.Lecturer != old.Lecturer {
+		lectures, err := db.GetLectures(bson.M{"group": group, "subject": subject.Key, "lecturer": old.Lecturer})
+		if err != nil {
+			log.Printf("error: %v", err)
+		}
+		for _, lecture := range lectures {
+			lecture.Lecturer = subject.Lecturer
+			if err := db.UpdateLecture(lecture.ID, lecture); err != nil {
+				log.Printf("error: %v", err)
+				continue
+			}
+			moved++
+		}
+	}
+	log.Printf("updated subject : %+v", subject)
+	bot.Send(tgbotapi.NewMessage(chatID, Tn(lang, "subject.updated", moved, subject.Key)))
+}
+
+// sets the name of a subject in a language from
+// "/subjectname <key> | <language> | <name>", an empty name removes it
+func TranslateSubject(db mdb.CatalogStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := splitFields(args, 3)
+	if fields[0] == "" || !slices.Contains(languages, fields[1]) {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.name_usage")))
+		return
+	}
+	subjects, err := db.GetSubjects(group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	subject, ok := subjects[fields[0]]
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.notfound", fields[0])))
+		return
+	}
+	names := maps.Clone(subject.Names)
+	if names == nil {
+		names = make(map[string]string)
+	}
+	if fields[2] == "" {
+		delete(names, fields[1])
+	} else {
+		names[fields[1]] = fields[2]
+	}
+	subject.Names = names
+	if err := db.UpdateSubject(subject); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.renamed", fields[1], subject.Key)))
+}
+
+// deletes a subject from "/deletesubject <key>"
+func DeleteSubject(db mdb.CatalogStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	key := strings.TrimSpace(args)
+	if key == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.delete_usage")))
+		return
+	}
+	if err := db.DeleteSubject(group, key); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "subject.deleted", key)))
+}
+
+// lists the subjects of the group
+func SendSubjects(db mdb.CatalogStore, chatID int64, group string, lang string, bot *tgbotapi.BotAPI) {
+	subjects, err := db.GetSubjects(group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	keys := make([]string, 0, len(subjects))
+	for key := range subjects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var content string
+	for _, key := range keys {
+		subject := subjects[key]
+		name := subject.Name
+		if translated := subject.Names[lang]; translated != "" {
+			name = translated
+		}
+		content += fmt.Sprintf("`%v` - %v, %v\n", subject.Key, name, subject.Lecturer)
+	}
+	if content == "" {
+		content = T(lang, "subjects.none")
+	}
+	msg := tgbotapi.NewMessage(chatID, T(lang, "subjects")+content)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	bot.Send(msg)
+}
+
+// replaces the periods from "/setperiods 8:00-9:40 9:55-11:35 ...", the
+// periods are numbered in the order given. periods still used by lectures
+// of any group can't be removed, the lectures are listed instead
+func SetPeriods(db mdb.Store, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		periods, err := db.GetPeriods()
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+			return
+		}
+		var current []string
+		for number := 1; number <= len(periods); number++ {
+			current = append(current, periods[number].String())
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "periods.usage", strings.Join(current, " "))))
+		return
+	}
+	periods := make(map[int]mdb.Period, len(fields))
+	for i, field := range fields {
+		period, err := mdb.ParsePeriod(i+1, field)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "periods.invalid", field)))
+			return
+		}
+		periods[i+1] = period
+	}
+	err := mdb.SetPeriodsChecked(db, periods)
+	var inUse *mdb.PeriodsInUseError
+	if errors.As(err, &inUse) {
+		bot.Send(tgbotapi.NewMessage(chatID, periodsInUse(db, group, inUse.Lectures, lang)))
+		return
+	}
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	log.Printf("periods set: %v", fields)
+	bot.Send(tgbotapi.NewMessage(chatID, Tn(lang, "periods.set", len(periods))))
+}
+
+// lists the lectures keeping periods from being removed, at most 10
+func periodsInUse(db mdb.CatalogStore, group string, lectures []mdb.Lecture, lang string) string {
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	text := Tn(lang, "periods.in_use", len(lectures))
+	for i, l := range lectures {
+		if i == 10 {
+			text += "\n" + T(lang, "periods.more", len(lectures)-i)
+			break
+		}
+		text += fmt.Sprintf("\n[ %v ] %v | %v", l.ID.Hex(), l.Group, lectureLine(l, cat, lang))
+	}
+	return text
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// formats /date accepts, the ones without a year mean the current year
+var dateLayouts = []string{mdb.DateLayout, "02.01.2006", "02.01"}
+
+// sends the timetable of the day the query names, "/date 2026-11-03 [+N]"
+// or with weekday "/day thursday [+N]"
+func SendDay(db mdb.Store, chatID int64, bot *tgbotapi.BotAPI, opt mdb.Args, query string, weekday bool, mm *MessageManager) {
+	parse, usage := ParseDateQuery, "date.usage"
+	if weekday {
+		parse, usage = ParseDayQuery, "date.day_usage"
+	}
+	date, err := parse(query, time.Now())
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(opt.Lang, usage)))
+		return
+	}
+	SendNav(db, chatID, Nav{Date: date, Opt: opt}, bot, mm)
+}
+
+// resolves "2026-11-03 [+N]", "03.11.2026" or "03.11" to the date, moved N
+// weeks ahead
+func ParseDateQuery(query string, now time.Time) (time.Time, error) {
+	words, weeks, err := splitQuery(query)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(words) != 1 {
+		return time.Time{}, fmt.Errorf("error: expected one date, got %q", query)
+	}
+	for _, layout := range dateLayouts {
+		date, err := time.ParseInLocation(layout, words[0], now.Location())
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "2006") {
+			date = time.Date(now.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location())
+		}
+		return date.AddDate(0, 0, 7*weeks), nil
+	}
+	return time.Time{}, fmt.Errorf("error: invalid date %q", words[0])
+}
+
+// resolves "thursday [+N]" to the nearest such weekday from today on, moved N
+// weeks ahead. the names of every language are understood
+func ParseDayQuery(query string, now time.Time) (time.Time, error) {
+	words, weeks, err := splitQuery(query)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(words) != 1 {
+		return time.Time{}, fmt.Errorf("error: expected one weekday, got %q", query)
+	}
+	day, ok := parseWeekday(words[0])
+	if !ok {
+		return time.Time{}, fmt.Errorf("error: invalid weekday %q", words[0])
+	}
+	ahead := (day - int(now.Weekday()) + 7) % 7
+	return DayStart(now).AddDate(0, 0, ahead+7*weeks), nil
+}
+
+// splits the query into its words and the "+N" week offset, skipping the
+// -l, -1, -2, -all flags
+func splitQuery(query string) (words []string, weeks int, err error) {
+	for _, field := range strings.Fields(query) {
+		switch {
+		case strings.HasPrefix(field, "+"):
+			if weeks, err = strconv.Atoi(field[1:]); err != nil {
+				return nil, 0, fmt.Errorf("error: invalid week offset %q", field)
+			}
+		case strings.HasPrefix(field, "-"):
+			continue
+		default:
+			words = append(words, field)
+		}
+	}
+	return words, weeks, nil
+}
+
+// finds the weekday by its full or short name in any language
+func parseWeekday(name string) (int, bool) {
+	name = strings.ToLower(name)
+	for _, lang := range languages {
+		for day := 0; day < 7; day++ {
+			if name == strings.ToLower(T(lang, fmt.Sprintf("day.%d", day))) || name == T(lang, fmt.Sprintf("day.%d.short", day)) {
+				return day, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// a sunday
+var testNow = time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC)
+
+func TestParseDateQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"2026-11-03", "2026-11-03", false},
+		{"03.11.2027", "2027-11-03", false},
+		{"03.11", "2026-11-03", false},
+		{"03.11 +1", "2026-11-10", false},
+		{"03.11 +2 -l -all", "2026-11-17", false},
+		{"", "", true},
+		{"03.11 04.11", "", true},
+		{"31.02", "", true},
+		{"tomorrow", "", true},
+		{"03.11 +x", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateQuery(tt.query, testNow)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDateQuery(%q) error %v, want error %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.Format("2006-01-02") != tt.want {
+			t.Errorf("ParseDateQuery(%q) = %v, want %v", tt.query, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
+
+func TestParseDayQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"четверг", "2026-10-22", false},
+		{"Thursday", "2026-10-22", false},
+		{"чацвер", "2026-10-22", false},
+		{"thu +1", "2026-10-29", false},
+		{"пн -2", "2026-10-19", false},
+		{"вс", "2026-10-18", false},
+		{"sunday +1", "2026-10-25", false},
+		{"", "", true},
+		{"funday", "", true},
+		{"mon tue", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDayQuery(tt.query, testNow)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDayQuery(%q) error %v, want error %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Format("2006-01-02") != tt.want || got.Hour() != 0 {
+			t.Errorf("ParseDayQuery(%q) = %v, want the start of %v", tt.query, got, tt.want)
+		}
+	}
+}
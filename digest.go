@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// a digest missed by more than this, e.g. while the bot was down, is
+// skipped for the day instead of arriving late
+const digestGrace = 2 * time.Hour
+
+// the moment the digest is due on the day of date. time.Date resolves
+// clock times skipped or repeated by a DST change
+func digestDue(sub mdb.Subscription, date time.Time) (time.Time, error) {
+	clock, err := mdb.ParseClock(sub.Time)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := date.Date()
+	return time.Date(y, m, d, clock/60, clock%60, 0, 0, date.Location()), nil
+}
+
+// sends the digests that are due. the date of the last digest is stored, so
+// a restart neither repeats nor loses the digest of the day
+func (s *Scheduler) digest(from, to time.Time) {
+	subscriptions, err := s.db.GetSubscriptions(bson.M{"kind": mdb.SubscriptionDigest})
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	today := to.Format(mdb.DateLayout)
+	for _, sub := range subscriptions {
+		if sub.LastSent == today {
+			continue
+		}
+		due, err := digestDue(sub, to)
+		if err != nil {
+			log.Printf("error: subscription %v: %v", sub.ID, err)
+			continue
+		}
+		if to.Before(due) || to.Sub(due) > digestGrace {
+			continue
+		}
+		sub.LastSent = today
+		if err := s.db.SaveSubscription(sub); err != nil {
+			log.Printf("error: %v", err)
+			continue
+		}
+		msg, err := BuildDigest(s.db, sub, due.AddDate(0, 0, 1))
+		if err != nil {
+			log.Printf("error: %v", err)
+			continue
+		}
+		s.send(sub, msg)
+	}
+}
+
+// renders the timetable of date for a digest subscription
+func BuildDigest(db mdb.Store, sub mdb.Subscription, date time.Time) (tgbotapi.MessageConfig, error) {
+	opt := mdb.Args{Long: sub.Long, Group: sub.SubGroup, StudyGroup: sub.Group, Lang: Language(db, sub.ChatID, sub.ChatID)}
+	schedule, err := GetDaySchedule(db, opt, date)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+	if len(schedule.Lectures) == 0 {
+		return tgbotapi.NewMessage(sub.ChatID, T(opt.Lang, "no_lectures.tomorrow")), nil
+	}
+	cat, err := mdb.GetCatalog(db, sub.Group)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+	msg := tgbotapi.NewMessage(sub.ChatID, FormatLectures(schedule.Lectures, dayName(cat, schedule.Day, opt.Lang), opt, cat))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	return msg, nil
+}
+
+// handles "/digest HH:MM [-1|-2|-all] [-l]", "/digest off" and "/digest"
+func HandleDigest(db mdb.SubscriptionStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		subs, err := db.GetSubscriptions(bson.M{"_id": mdb.SubscriptionID(chatID, mdb.SubscriptionDigest)})
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		if len(subs) == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "digest.status.off")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "digest.status", subs[0].Time, subGroupName(subs[0].SubGroup, lang))))
+		return
+	}
+	if fields[0] == "off" {
+		if err := db.DeleteSubscription(chatID, mdb.SubscriptionDigest); err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "digest.already_off")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "digest.off")))
+		return
+	}
+	if _, err := mdb.ParseClock(fields[0]); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "digest.usage")))
+		return
+	}
+	arg := ParseArgs(strings.Join(fields[1:], " "))
+	sub := mdb.Subscription{
+		ChatID:   chatID,
+		Kind:     mdb.SubscriptionDigest,
+		Group:    group,
+		SubGroup: arg.Group,
+		Time:     fields[0],
+		Long:     arg.Long,
+	}
+	// a time already passed today starts tomorrow
+	if due, _ := digestDue(sub, time.Now()); !time.Now().Before(due) {
+		sub.LastSent = time.Now().Format(mdb.DateLayout)
+	}
+	if err := db.SaveSubscription(sub); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	log.Printf("chat %v subscribed to digests: %+v", chatID, sub)
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "digest.on", sub.Time, subGroupName(sub.SubGroup, lang))))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"gopkg.in/yaml.v3"
+)
+
+// converts a lecture to the row the importer reads back
+func LectureToRow(l mdb.Lecture) LectureRow {
+	return LectureRow{
+		ID:       Cell(l.ID.Hex()),
+		Group:    Cell(l.Group),
+		Week:     Cell(l.Week),
+		Day:      Cell(fmt.Sprint(l.Day)),
+		Period:   Cell(fmt.Sprint(l.Time)),
+		Subject:  Cell(l.Subject),
+		Type:     Cell(l.Type),
+		Room:     Cell(l.Room),
+		Lecturer: Cell(l.Lecturer),
+		SubGroup: Cell(l.SubGroup),
+	}
+}
+
+// encodes rows as csv, json or yaml
+func EncodeRows(format string, rows []LectureRow) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case "csv":
+		w := csv.NewWriter(&buf)
+		w.Write(rowColumns)
+		for _, row := range rows {
+			record := make([]string, len(rowColumns))
+			for i, column := range rowColumns {
+				record[i] = string(*row.field(column))
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+	case "json":
+		if rows == nil {
+			rows = []LectureRow{}
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			return nil, err
+		}
+	case "yaml":
+		if err := yaml.NewEncoder(&buf).Encode(rows); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q, use csv, json or yaml", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// exports the lectures matching filter
+func ExportLectures(db mdb.LectureStore, filter bson.M, format string) ([]byte, error) {
+	lectures, err := db.GetLectures(filter)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]LectureRow, len(lectures))
+	for i, lecture := range lectures {
+		rows[i] = LectureToRow(lecture)
+	}
+	return EncodeRows(format, rows)
+}
+
+// runs "export [-group key] [-format csv|json|yaml] [-o file]"
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	group := flags.String("group", "", "only export this group, every group by default")
+	format := flags.String("format", "", "csv, json or yaml, detected from the output file name, json by default")
+	output := flags.String("o", "", "output file, standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = "json"
+		if *output != "" {
+			var err error
+			if *format, err = FormatFromName(*output); err != nil {
+				return err
+			}
+		}
+	}
+	db, closeDb, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+	filter := bson.M{}
+	if *group != "" {
+		filter["group"] = *group
+	}
+	data, err := ExportLectures(db, filter, *format)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0644)
+}
+
+// sends the lectures of the group as a file from "/export [csv|json|yaml]"
+func SendExport(db mdb.LectureStore, chatID int64, group string, args string, bot *tgbotapi.BotAPI) {
+	format := strings.ToLower(strings.TrimSpace(args))
+	if format == "" {
+		format = "csv"
+	}
+	data, err := ExportLectures(db, bson.M{"group": group}, format)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	name := fmt.Sprintf("timetable-%v-%v.%v", group, time.Now().Format("2006-01-02"), format)
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	doc.Caption = "Send it back with /import to restore"
+	bot.Send(doc)
+}
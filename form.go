@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// replies that control a form instead of answering its field. the buttons
+// show them in the language of the user, "form.<control>", and both the
+// english word and the label are accepted
+const (
+	formBack   = "back"
+	formSkip   = "skip"
+	formCancel = "cancel"
+)
+
+// Choice is a button of a field menu and the value it stands for
+type Choice struct {
+	Label string
+	Value string
+}
+
+// FormContext is what fields and forms may use while handling a reply
+type FormContext struct {
+	DB    mdb.Store
+	Group string
+	Cat   mdb.Catalog
+	Bot   *tgbotapi.BotAPI
+	// the language of the user answering the form
+	Lang string
+}
+
+// Field is one step of a form
+type Field struct {
+	// the key of the message asking for the field
+	Prompt string
+	// shown above the prompt, e.g. the lecture the form works on
+	Details func(ctx FormContext, s mdb.Session) string
+	// the options offered as buttons, in order. nil for free text
+	Choices func(ctx FormContext) []Choice
+	// validates the value, the Value of a choice or the text typed in, and
+	// stores it in the session
+	Set func(ctx FormContext, s *mdb.Session, value string) error
+	// the field may be skipped, keeping what the session already holds
+	Skip bool
+	// stored when the field is skipped, implies Skip
+	Default string
+	// reports whether the field needs an answer, the ones that don't are
+	// passed by. nil always asks
+	Ask func(ctx FormContext, s mdb.Session) bool
+}
+
+func (f Field) skippable() bool {
+	return f.Skip || f.Default != ""
+}
+
+func (f Field) asked(ctx FormContext, s mdb.Session) bool {
+	return f.Ask == nil || f.Ask(ctx, s)
+}
+
+// Form is a wizard asking its fields one after another
+type Form struct {
+	Fields []Field
+	// runs once every field is answered and returns the reply to the user
+	Submit func(ctx FormContext, s mdb.Session) (string, error)
+	// the key of the message sent when the form is cancelled
+	Cancelled string
+}
+
+// the wizards by the kind of session they run in
+var forms = map[string]*Form{
+	mdb.SessionAddLecture:    &addLectureForm,
+	mdb.SessionEditLecture:   &editLectureForm,
+	mdb.SessionDeleteLecture: &deleteLectureForm,
+}
+
+// starts the wizard of the session kind at the step the session is at
+func StartForm(ctx FormContext, sessions *Sessions, session mdb.Session, chatID int64) {
+	form, ok := forms[session.Kind]
+	if !ok {
+		log.Printf("error: no form for session kind %q", session.Kind)
+		return
+	}
+	sessions.Save(session)
+	form.prompt(ctx, session, chatID, "")
+}
+
+// feeds a message to the wizard the user runs in the chat, if any
+func HandleForm(db mdb.Store, sessions *Sessions, group string, lang string, update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	session, ok := sessions.Get(chatID, userID)
+	if !ok {
+		return
+	}
+	form, ok := forms[session.Kind]
+	if !ok {
+		return
+	}
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	ctx := FormContext{DB: db, Group: group, Cat: cat, Bot: bot, Lang: lang}
+	text := strings.TrimSpace(update.Message.Text)
+	if session.Step >= len(form.Fields) {
+		session.Step = len(form.Fields) - 1
+	}
+	field := form.Fields[session.Step]
+
+	switch control(lang, text) {
+	case formCancel:
+		sessions.Delete(chatID, userID)
+		msg := tgbotapi.NewMessage(chatID, T(lang, form.Cancelled))
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+		bot.Send(msg)
+		return
+	case formBack:
+		if session.Step > 0 {
+			session.Step--
+		}
+		for session.Step > 0 && !form.Fields[session.Step].asked(ctx, session) {
+			session.Step--
+		}
+		sessions.Save(session)
+		form.prompt(ctx, session, chatID, "")
+		return
+	case formSkip:
+		if !field.skippable() {
+			form.prompt(ctx, session, chatID, T(lang, "form.no_skip"))
+			return
+		}
+		if field.Default != "" {
+			err = field.Set(ctx, &session, field.Default)
+		}
+	default:
+		err = field.Set(ctx, &session, field.value(ctx, text))
+	}
+	if err != nil {
+		form.prompt(ctx, session, chatID, err.Error())
+		return
+	}
+
+	session.Step++
+	for session.Step < len(form.Fields) && !form.Fields[session.Step].asked(ctx, session) {
+		session.Step++
+	}
+	if session.Step < len(form.Fields) {
+		sessions.Save(session)
+		form.prompt(ctx, session, chatID, "")
+		return
+	}
+	sessions.Delete(chatID, userID)
+	reply, err := form.Submit(ctx, session)
+	if err != nil {
+		reply = errorText(err, lang)
+	}
+	msg := tgbotapi.NewMessage(chatID, reply)
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	bot.Send(msg)
+}
+
+// the form control the reply stands for, "" for an answer
+func control(lang, text string) string {
+	for _, c := range []string{formBack, formSkip, formCancel} {
+		if strings.EqualFold(text, c) || strings.EqualFold(text, T(lang, "form."+c)) {
+			return c
+		}
+	}
+	return ""
+}
+
+// maps the text of a pressed button to its value. typed text that matches
+// no button is passed on for Set to reject
+func (f Field) value(ctx FormContext, text string) string {
+	if f.Choices == nil {
+		return text
+	}
+	for _, choice := range f.Choices(ctx) {
+		if strings.EqualFold(text, choice.Label) {
+			return choice.Value
+		}
+	}
+	return text
+}
+
+// asks the current field, after a problem with the previous reply if any
+func (form *Form) prompt(ctx FormContext, session mdb.Session, chatID int64, problem string) {
+	field := form.Fields[session.Step]
+	text := T(ctx.Lang, field.Prompt)
+	if field.Default != "" {
+		text += "\n" + T(ctx.Lang, "form.default", T(ctx.Lang, "form."+formSkip), field.Default)
+	} else if field.Skip {
+		text += "\n" + T(ctx.Lang, "form.keep", T(ctx.Lang, "form."+formSkip))
+	}
+	if field.Details != nil {
+		text = field.Details(ctx, session) + "\n" + text
+	}
+	if problem != "" {
+		text = problem + "\n" + text
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = form.keyboard(ctx, session)
+	ctx.Bot.Send(msg)
+}
+
+// one button per choice of the field, then the form controls
+func (form *Form) keyboard(ctx FormContext, session mdb.Session) tgbotapi.ReplyKeyboardMarkup {
+	field := form.Fields[session.Step]
+	var rows [][]tgbotapi.KeyboardButton
+	if field.Choices != nil {
+		for _, choice := range field.Choices(ctx) {
+			rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(choice.Label)))
+		}
+	}
+	var controls []tgbotapi.KeyboardButton
+	if session.Step > 0 {
+		controls = append(controls, tgbotapi.NewKeyboardButton(T(ctx.Lang, "form."+formBack)))
+	}
+	if field.skippable() {
+		controls = append(controls, tgbotapi.NewKeyboardButton(T(ctx.Lang, "form."+formSkip)))
+	}
+	controls = append(controls, tgbotapi.NewKeyboardButton(T(ctx.Lang, "form."+formCancel)))
+	rows = append(rows, controls)
+	return tgbotapi.NewOneTimeReplyKeyboard(rows...)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// returns the group the chat has selected, or the default group
+func ChatGroup(db mdb.ChatStore, chatID int64, defaultGroup string) string {
+	chat, err := db.GetChat(chatID)
+	if err != nil {
+		if !errors.Is(err, mdb.ErrNotFound) {
+			log.Printf("error: %v", err)
+		}
+		return defaultGroup
+	}
+	if chat.Group == "" {
+		return defaultGroup
+	}
+	return chat.Group
+}
+
+// lists the groups, or switches the chat to the group given in args
+func SelectGroup(db mdb.Store, chatID int64, current string, lang string, args string, bot *tgbotapi.BotAPI) {
+	key := strings.TrimSpace(args)
+	if key == "" {
+		groups, err := db.GetGroups()
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		var list string
+		for _, g := range groups {
+			mark := ""
+			if g.Key == current {
+				mark = " ✅"
+			}
+			list += fmt.Sprintf("`%v` - %v%v\n", g.Key, g.Name, mark)
+		}
+		msg := tgbotapi.NewMessage(chatID, T(lang, "groups", list))
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		bot.Send(msg)
+		return
+	}
+	g, err := db.GetGroup(key)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "group.notfound", key)))
+		return
+	}
+	chat, err := db.GetChat(chatID)
+	if err != nil && !errors.Is(err, mdb.ErrNotFound) {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	chat.ID = chatID
+	chat.Group = g.Key
+	if err := db.SaveChat(chat); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "group.selected", g.Name)))
+}
+
+// creates a group from "/addgroup <key> <name>"
+func AddGroup(db mdb.GroupStore, chatID int64, lang string, args string, bot *tgbotapi.BotAPI) {
+	key, name, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if key == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "group.usage")))
+		return
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = key
+	}
+	if err := db.InsertGroup(mdb.Group{Key: key, Name: name}); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "group.added", key)))
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// entries /history shows
+const historyLimit = 15
+
+// the longest text telegram accepts in a message
+const maxMessageLength = 4096
+
+// one line naming the change of the entry
+func describeAudit(e mdb.AuditEntry, lang string) string {
+	text := T(lang, "history.entry", e.ID.Hex(), e.Time.Local().Format("02.01 15:04"), e.UserID,
+		T(lang, "audit."+e.Action), T(lang, "audit."+e.Kind()), e.Key)
+	if !e.Reverts.IsZero() {
+		text += T(lang, "history.undo_of", e.Reverts.Hex())
+	}
+	if e.Undone {
+		text += T(lang, "history.undone")
+	}
+	return text
+}
+
+// what the change of the entry did to its document
+func auditDetails(e mdb.AuditEntry, cat mdb.Catalog, lang string) string {
+	before, after := e.Before, e.After
+	switch {
+	case before.Lecture != nil && after.Lecture != nil:
+		return LectureDiff(*before.Lecture, *after.Lecture, cat, lang)
+	case before.Lecture != nil:
+		return fmt.Sprintf("- `%v`\n", lectureLine(*before.Lecture, cat, lang))
+	case after.Lecture != nil:
+		return fmt.Sprintf("+ `%v`\n", lectureLine(*after.Lecture, cat, lang))
+	case before.Subject != nil && after.Subject != nil:
+		return fmt.Sprintf("`%v | %v` → `%v | %v`\n", before.Subject.Name, before.Subject.Lecturer, after.Subject.Name, after.Subject.Lecturer)
+	case before.Subject != nil:
+		return fmt.Sprintf("- `%v | %v`\n", before.Subject.Name, before.Subject.Lecturer)
+	case after.Subject != nil:
+		return fmt.Sprintf("+ `%v | %v`\n", after.Subject.Name, after.Subject.Lecturer)
+	case before.Override != nil:
+		return fmt.Sprintf("- `%v %v`\n", before.Override.Date, before.Override.Kind)
+	case after.Override != nil:
+		return fmt.Sprintf("+ `%v %v`\n", after.Override.Date, after.Override.Kind)
+	case before.Grant != nil || after.Grant != nil:
+		return fmt.Sprintf("`%v` → `%v`\n", grantRole(before.Grant), grantRole(after.Grant))
+	case before.Group != nil || after.Group != nil:
+		return fmt.Sprintf("`%v` → `%v`\n", groupName(before.Group), groupName(after.Group))
+	case after.Periods != nil:
+		return fmt.Sprintf("`%v` → `%v`\n", periodList(before.Periods), periodList(after.Periods))
+	case after.Days != nil:
+		return fmt.Sprintf("`%v` → `%v`\n", before.Days, after.Days)
+	case after.Types != nil:
+		return fmt.Sprintf("`%v` → `%v`\n", before.Types, after.Types)
+	case after.Lectures != nil:
+		return Tn(lang, "lectures", len(after.Lectures)) + "\n"
+	}
+	return ""
+}
+
+func grantRole(g *mdb.Grant) string {
+	if g == nil {
+		return "-"
+	}
+	return g.Role
+}
+
+func groupName(g *mdb.Group) string {
+	if g == nil {
+		return "-"
+	}
+	return g.Name
+}
+
+func periodList(periods map[int]mdb.Period) string {
+	var list []string
+	for _, number := range slices.Sorted(maps.Keys(periods)) {
+		list = append(list, periods[number].String())
+	}
+	return strings.Join(list, " ")
+}
+
+// the entries of the group and the changes of the whole bot
+func historyFilter(group string) bson.M {
+	return bson.M{"group": bson.M{"$in": []string{group, ""}}}
+}
+
+// handles "/history", the latest changes made to the group, and
+// "/history <id>", the latest changes of the lecture, override or subject
+func HandleHistory(db mdb.Store, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	key := strings.TrimSpace(args)
+	filter := historyFilter(group)
+	if key != "" {
+		filter["key"] = key
+	}
+	entries, err := db.GetAudit(filter, historyLimit)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	if len(entries) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "history.none")))
+		return
+	}
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	blocks := []string{T(lang, "history.title") + "\n"}
+	for _, e := range entries {
+		block := describeAudit(e, lang) + "\n"
+		if key != "" {
+			block += auditDetails(e, cat, lang)
+		}
+		blocks = append(blocks, block)
+	}
+	if key == "" {
+		blocks = append(blocks, T(lang, "history.footer"))
+	}
+	sendBlocks(chatID, blocks, bot)
+}
+
+// sends the blocks separated by blank lines in as few messages as telegram
+// allows, a block is never split
+func sendBlocks(chatID int64, blocks []string, bot *tgbotapi.BotAPI) {
+	var text string
+	send := func() {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		if _, err := bot.Send(msg); err != nil {
+			log.Printf("sending error : %v", err)
+		}
+		text = ""
+	}
+	for _, block := range blocks {
+		if len(block) > maxMessageLength {
+			block = block[:strings.LastIndex(block[:maxMessageLength], "\n")+1]
+		}
+		if text != "" && len(text)+len(block)+1 > maxMessageLength {
+			send()
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += block
+	}
+	if text != "" {
+		send()
+	}
+}
+
+// handles "/undo", which reverts the latest change the admin made to the
+// group that is not undone yet, and "/undo <id>", which reverts the change
+// with the id. changes made by undo are skipped, so repeating it walks back
+// through the admin's history. changes of the whole bot can only be undone
+// by owners
+func HandleUndo(db mdb.Store, chatID int64, userID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	filter := historyFilter(group)
+	filter["undone"] = false
+	if id := strings.TrimSpace(args); id != "" {
+		ID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "undo.usage")))
+			return
+		}
+		filter["_id"] = ID
+	} else {
+		filter["user_id"] = userID
+		filter["reverts"] = bson.M{"$exists": false}
+	}
+	entries, err := db.GetAudit(filter, 1)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	if len(entries) == 0 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "undo.none")))
+		return
+	}
+	e := entries[0]
+	if !HasRole(db, group, userID, undoRole(e)) {
+		log.Printf("user %d lacks role %v to undo %v", userID, undoRole(e), e.ID.Hex())
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "undo.denied", undoRole(e))))
+		return
+	}
+	err = mdb.Undo(db, e, userID)
+	if errors.Is(err, mdb.ErrLastOwner) {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "revoke.last_owner")))
+		return
+	}
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	switch {
+	case e.Action == mdb.AuditInsert && e.After.Lecture != nil:
+		NotifyLectureDelete(db, *e.After.Lecture, bot)
+	case e.Action == mdb.AuditUpdate && e.After.Lecture != nil:
+		NotifyLectureUpdate(db, *e.After.Lecture, *e.Before.Lecture, bot)
+	}
+	msg := tgbotapi.NewMessage(chatID, T(lang, "undo.done")+"\n"+describeAudit(e, lang))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	bot.Send(msg)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+// starts the http server in the background when TIMETABLE_HTTP_ADDR is set
+func StartHTTPServer(db mdb.Store, defaultGroup string) {
+	addr := os.Getenv("TIMETABLE_HTTP_ADDR")
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /calendar/{file}", CalendarHandler(db))
+	api := &API{db: db, defaultGroup: defaultGroup}
+	api.Register(mux)
+	go func() {
+		log.Printf("http server listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("http server error: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// semesters without SEMESTER_END_DATE are this long
+const semesterWeeks = 18
+
+// determines the last day of the semester
+func GetSemesterEnd() (time.Time, error) {
+	if end := os.Getenv("SEMESTER_END_DATE"); end != "" {
+		return parseDate(end)
+	}
+	start, err := parseDate(os.Getenv("SEMESTER_START_DATE"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.AddDate(0, 0, 7*semesterWeeks-1), nil
+}
+
+// Occurrence is a lecture on a calendar date
+type Occurrence struct {
+	Lecture mdb.Lecture
+	Date    time.Time
+	Start   time.Time
+	End     time.Time
+}
+
+// expands the 4-week cycle into the dated lectures between from and to,
+// both inclusive, with the overrides of each date applied. days before the
+// semester starts have no lectures
+func ExpandLectures(db mdb.Store, opt mdb.Args, cat mdb.Catalog, from, to time.Time) ([]Occurrence, error) {
+	lectures, err := db.GetLectures(mdb.LectureQuery{Group: opt.StudyGroup, SubGroup: opt.Group}.Filter())
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := db.GetOverrides(bson.M{"group": opt.StudyGroup})
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string][]mdb.Override)
+	for _, o := range overrides {
+		byDate[o.Date] = append(byDate[o.Date], o)
+	}
+	semesterStart := os.Getenv("SEMESTER_START_DATE")
+	// there are no lectures before the semester starts
+	if start, err := time.ParseInLocation("2006-01-02", semesterStart, from.Location()); err == nil && from.Before(start) {
+		from = start
+	}
+	var out []Occurrence
+	for date := DayStart(from); !date.After(to); date = date.AddDate(0, 0, 1) {
+		week, err := GetWeekAt(semesterStart, date)
+		if err != nil {
+			return nil, err
+		}
+		var day []mdb.Lecture
+		for _, lecture := range lectures {
+			if lecture.Day == int(date.Weekday()) && (lecture.Week == "0" || lecture.Week == fmt.Sprint(week)) {
+				day = append(day, lecture)
+			}
+		}
+		for _, lecture := range mdb.ApplyOverrides(day, byDate[date.Format(mdb.DateLayout)], opt.Group) {
+			start, end, err := cat.Periods[lecture.Time].Minutes()
+			if err != nil {
+				log.Printf("error: lecture %v: %v", lecture.ID.Hex(), err)
+				continue
+			}
+			y, m, d := date.Date()
+			out = append(out, Occurrence{
+				Lecture: lecture,
+				Date:    date,
+				Start:   time.Date(y, m, d, 0, start, 0, 0, date.Location()),
+				End:     time.Date(y, m, d, 0, end, 0, 0, date.Location()),
+			})
+		}
+	}
+	return out, nil
+}
+
+// builds an iCalendar file of the lectures from the given day until the end
+// of the semester. times are computed in the local timezone of the
+// deployment and written in UTC
+func BuildICS(db mdb.Store, opt mdb.Args, from time.Time) ([]byte, error) {
+	cat, err := mdb.GetCatalog(db, opt.StudyGroup)
+	if err != nil {
+		return nil, err
+	}
+	end, err := GetSemesterEnd()
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := ExpandLectures(db, opt, cat, from, end)
+	if err != nil {
+		return nil, err
+	}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//RemyJohnny//timetable//RU")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+escapeICS(T(opt.Lang, "ics.name", opt.StudyGroup, subGroupName(opt.Group, opt.Lang))))
+	if tz := os.Getenv("TZ"); tz != "" {
+		writeICSLine(&b, "X-WR-TIMEZONE:"+tz)
+	}
+	for _, o := range occurrences {
+		l := o.Lecture
+		subject := subjectName(cat, l.Subject, opt.Lang)
+		summary := fmt.Sprintf("%v (%v)", subject, l.Type)
+		description := T(opt.Lang, "ics.description", subject, l.Type, l.Lecturer, l.SubGroup)
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, fmt.Sprintf("UID:%v-%v@timetable", l.ID.Hex(), o.Date.Format("20060102")))
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART:"+o.Start.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "DTEND:"+o.End.UTC().Format("20060102T150405Z"))
+		writeICSLine(&b, "SUMMARY:"+escapeICS(summary))
+		writeICSLine(&b, "LOCATION:"+escapeICS(l.Room))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICS(description))
+		if l.Status == mdb.StatusCancelled {
+			writeICSLine(&b, "STATUS:CANCELLED")
+		} else {
+			writeICSLine(&b, "STATUS:CONFIRMED")
+		}
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String()), nil
+}
+
+// escapes a TEXT value (RFC 5545 3.3.11)
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// writes a content line folded at 75 octets (RFC 5545 3.1)
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// do not split a multi-byte character
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// sends the timetable as an .ics file
+func SendICS(db mdb.Store, chatID int64, bot *tgbotapi.BotAPI, opt mdb.Args) {
+	data, err := BuildICS(db, opt, time.Now())
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	name := "timetable.ics"
+	if opt.Group != "" {
+		name = fmt.Sprintf("timetable-%v.ics", opt.Group)
+	}
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	doc.Caption = T(opt.Lang, "ics.caption")
+	if _, err := bot.Send(doc); err != nil {
+		log.Printf("sending error : %v", err)
+	}
+}
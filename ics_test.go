@@ -0,0 +1,78 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+func TestExpandLectures(t *testing.T) {
+	t.Setenv("SEMESTER_START_DATE", "2026-09-01")
+	db := mdb.NewMemDb()
+	if err := prepareStore(db, "g"); err != nil {
+		t.Fatal(err)
+	}
+	lectures := []mdb.Lecture{
+		{Week: "0", Day: 2, Time: 1, Subject: "ОМО", Type: "ЛК", Room: "101", SubGroup: "0", Group: "g"},
+		{Week: "2", Day: 3, Time: 2, Subject: "ТЭ", Type: "ЛР", Room: "102", SubGroup: "1", Group: "g"},
+		// the semester starts on a tuesday, the mondays of a week end it
+		{Week: "1", Day: 1, Time: 3, Subject: "ФК", Type: "ПЗ", Room: "103", SubGroup: "2", Group: "g"},
+		{Week: "0", Day: 2, Time: 1, Subject: "АЯ", Type: "ЛК", Room: "104", SubGroup: "0", Group: "other"},
+	}
+	for _, l := range lectures {
+		if err := db.InsertLecture(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cat, err := mdb.GetCatalog(db, "g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(date string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		subGroup string
+		from, to string
+		want     []string
+	}{
+		{"first weeks of subgroup 1", "1", "2026-08-24", "2026-09-13", []string{"2026-09-01 ОМО", "2026-09-08 ОМО", "2026-09-09 ТЭ"}},
+		{"first weeks of subgroup 2", "2", "2026-08-24", "2026-09-13", []string{"2026-09-01 ОМО", "2026-09-07 ФК", "2026-09-08 ОМО"}},
+		{"next cycle", "2", "2026-09-28", "2026-10-05", []string{"2026-09-29 ОМО", "2026-10-05 ФК"}},
+		{"all subgroups", "", "2026-09-07", "2026-09-09", []string{"2026-09-07 ФК", "2026-09-08 ОМО", "2026-09-09 ТЭ"}},
+		{"before the semester", "", "2026-08-01", "2026-08-31", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := mdb.Args{Group: tt.subGroup, StudyGroup: "g"}
+			occurrences, err := ExpandLectures(db, opt, cat, day(tt.from), day(tt.to))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, o := range occurrences {
+				got = append(got, o.Date.Format("2006-01-02")+" "+o.Lecture.Subject)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	occurrences, err := ExpandLectures(db, mdb.Args{StudyGroup: "g"}, cat, day("2026-09-01"), day("2026-09-01"))
+	if err != nil || len(occurrences) != 1 {
+		t.Fatalf("got %v, %v", occurrences, err)
+	}
+	o := occurrences[0]
+	if o.Start.Format("2006-01-02 15:04") != "2026-09-01 08:00" || o.End.Format("15:04") != "09:40" {
+		t.Errorf("first period runs %v - %v, want 08:00 - 09:40", o.Start, o.End)
+	}
+}
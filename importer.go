@@ -0,0 +1,572 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/yaml.v3"
+)
+
+// Cell is a field of an imported row. json numbers are accepted as well as
+// strings, so "day": 2 and "day": "2" mean the same
+type Cell string
+
+func (c *Cell) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Cell(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("expected a string or a number, got %s", data)
+	}
+	*c = Cell(n.String())
+	return nil
+}
+
+// LectureRow is a lecture as it is written in import and export files.
+// day, period and subject may be given by number/key or by name
+type LectureRow struct {
+	ID       Cell `json:"id,omitempty" yaml:"id,omitempty"`
+	Group    Cell `json:"group,omitempty" yaml:"group,omitempty"`
+	Week     Cell `json:"week" yaml:"week"`
+	Day      Cell `json:"day" yaml:"day"`
+	Period   Cell `json:"period" yaml:"period"`
+	Subject  Cell `json:"subject" yaml:"subject"`
+	Type     Cell `json:"type" yaml:"type"`
+	Room     Cell `json:"room" yaml:"room"`
+	Lecturer Cell `json:"lecturer,omitempty" yaml:"lecturer,omitempty"`
+	SubGroup Cell `json:"sub_group" yaml:"sub_group"`
+}
+
+// the csv columns, in the order they are exported
+var rowColumns = []string{"id", "group", "week", "day", "period", "subject", "type", "room", "lecturer", "sub_group"}
+
+func (r *LectureRow) field(column string) *Cell {
+	switch column {
+	case "id":
+		return &r.ID
+	case "group":
+		return &r.Group
+	case "week":
+		return &r.Week
+	case "day":
+		return &r.Day
+	case "period":
+		return &r.Period
+	case "subject":
+		return &r.Subject
+	case "type":
+		return &r.Type
+	case "room":
+		return &r.Room
+	case "lecturer":
+		return &r.Lecturer
+	case "sub_group", "subgroup":
+		return &r.SubGroup
+	}
+	return nil
+}
+
+// RowError is a problem with one row of an imported file
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %v: %v", e.Row, e.Err)
+}
+
+// detects the format of a file from its name
+func FormatFromName(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return "csv", nil
+	case ".json":
+		return "json", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	}
+	return "", fmt.Errorf("unknown file format %q, use .csv, .json or .yaml", filepath.Ext(name))
+}
+
+// parses the rows of a csv, json or yaml file. the returned row numbers are
+// the lines of a csv file and the positions in a json or yaml list
+func ParseRows(format string, data []byte) ([]LectureRow, []int, error) {
+	var rows []LectureRow
+	switch format {
+	case "csv":
+		return parseCSV(data)
+	case "json":
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, nil, fmt.Errorf("error decoding json: %w", err)
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(data, &rows); err != nil {
+			return nil, nil, fmt.Errorf("error decoding yaml: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q", format)
+	}
+	numbers := make([]int, len(rows))
+	for i := range rows {
+		numbers[i] = i + 1
+	}
+	return rows, numbers, nil
+}
+
+func parseCSV(data []byte) ([]LectureRow, []int, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	// spreadsheets in russian locales separate with semicolons
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Contains(firstLine, []byte(";")) && !bytes.Contains(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("error decoding csv: missing header")
+	}
+	header := records[0]
+	var probe LectureRow
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if probe.field(header[i]) == nil {
+			return nil, nil, fmt.Errorf("error decoding csv: unknown column %q", column)
+		}
+	}
+	var rows []LectureRow
+	var numbers []int
+	for i, record := range records[1:] {
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		var row LectureRow
+		for j, value := range record {
+			if j < len(header) {
+				*row.field(header[j]) = Cell(value)
+			}
+		}
+		rows = append(rows, row)
+		numbers = append(numbers, i+2)
+	}
+	return rows, numbers, nil
+}
+
+// Importer turns rows into lectures, checking them against the catalog of
+// their group
+type Importer struct {
+	db mdb.Store
+	// rows without a group go to this group
+	group string
+	// reject rows of other groups
+	onlyGroup bool
+	// the language of the row errors
+	lang     string
+	catalogs map[string]mdb.Catalog
+}
+
+func NewImporter(db mdb.Store, group string, onlyGroup bool, lang string) *Importer {
+	return &Importer{db: db, group: group, onlyGroup: onlyGroup, lang: lang, catalogs: make(map[string]mdb.Catalog)}
+}
+
+func (im *Importer) catalog(group string) (mdb.Catalog, error) {
+	if cat, ok := im.catalogs[group]; ok {
+		return cat, nil
+	}
+	if _, err := im.db.GetGroup(group); err != nil {
+		return mdb.Catalog{}, err
+	}
+	cat, err := mdb.GetCatalog(im.db, group)
+	if err != nil {
+		return mdb.Catalog{}, err
+	}
+	im.catalogs[group] = cat
+	return cat, nil
+}
+
+// converts every row, collecting the errors of all rows
+func (im *Importer) Lectures(rows []LectureRow, numbers []int) ([]mdb.Lecture, []RowError) {
+	var lectures []mdb.Lecture
+	var errs []RowError
+	seen := make(map[primitive.ObjectID]int)
+	for i, row := range rows {
+		lecture, err := im.lecture(row)
+		if err == nil && !lecture.ID.IsZero() {
+			if first, ok := seen[lecture.ID]; ok {
+				err = errors.New(T(im.lang, "import.id_used", lecture.ID.Hex(), first))
+			}
+			seen[lecture.ID] = numbers[i]
+		}
+		if err != nil {
+			errs = append(errs, RowError{Row: numbers[i], Err: err})
+			continue
+		}
+		lectures = append(lectures, lecture)
+	}
+	return lectures, errs
+}
+
+func (im *Importer) lecture(row LectureRow) (mdb.Lecture, error) {
+	get := func(c Cell) string { return strings.TrimSpace(string(c)) }
+	var lecture mdb.Lecture
+	if id := get(row.ID); id != "" {
+		ID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return mdb.Lecture{}, errors.New(T(im.lang, "import.invalid_id", id))
+		}
+		lecture.ID = ID
+	}
+	lecture.Group = get(row.Group)
+	if lecture.Group == "" {
+		lecture.Group = im.group
+	}
+	if im.onlyGroup && lecture.Group != im.group {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.other_group", lecture.Group, im.group))
+	}
+	if im.onlyGroup && !lecture.ID.IsZero() {
+		// the id must not reach a lecture of another group, and ids that
+		// are not stored can't be chosen by the file
+		stored, err := im.db.GetLectures(bson.M{"_id": lecture.ID})
+		if err != nil {
+			return mdb.Lecture{}, err
+		}
+		if len(stored) == 0 {
+			lecture.ID = primitive.NilObjectID
+		} else if stored[0].Group != im.group {
+			return mdb.Lecture{}, errors.New(T(im.lang, "import.other_group_id", lecture.ID.Hex(), stored[0].Group, im.group))
+		}
+	}
+	cat, err := im.catalog(lecture.Group)
+	if err != nil {
+		return mdb.Lecture{}, err
+	}
+
+	lecture.Week = get(row.Week)
+	if _, ok := mdb.Weeks[lecture.Week]; !ok {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.invalid_week", lecture.Week))
+	}
+
+	day := get(row.Day)
+	if n, err := strconv.Atoi(day); err == nil {
+		if _, ok := cat.Days[n]; ok {
+			lecture.Day = n
+		}
+	}
+	for n, name := range cat.Days {
+		if strings.EqualFold(day, name) {
+			lecture.Day = n
+		}
+	}
+	if lecture.Day == 0 {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.invalid_day", day))
+	}
+
+	period := get(row.Period)
+	if n, err := strconv.Atoi(period); err == nil {
+		if _, ok := cat.Periods[n]; ok {
+			lecture.Time = n
+		}
+	}
+	for n, p := range cat.Periods {
+		if period == p.String() {
+			lecture.Time = n
+		}
+	}
+	if lecture.Time == 0 {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.invalid_period", period))
+	}
+
+	subjectName := get(row.Subject)
+	subject, ok := cat.Subjects[subjectName]
+	if !ok {
+		for _, s := range cat.Subjects {
+			if strings.EqualFold(subjectName, s.Name) {
+				subject, ok = s, true
+			}
+		}
+	}
+	if !ok {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.unknown_subject", subjectName))
+	}
+	lecture.Subject = subject.Key
+	lecture.Lecturer = get(row.Lecturer)
+	if lecture.Lecturer == "" {
+		lecture.Lecturer = subject.Lecturer
+	}
+
+	lecture.Type = get(row.Type)
+	if _, ok := cat.Types[lecture.Type]; !ok {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.invalid_type", lecture.Type))
+	}
+
+	lecture.Room = get(row.Room)
+	if lecture.Room == "" {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.missing_room"))
+	}
+
+	lecture.SubGroup = get(row.SubGroup)
+	if lecture.SubGroup == "" {
+		lecture.SubGroup = "0"
+	}
+	if _, ok := mdb.SubGroup[lecture.SubGroup]; !ok {
+		return mdb.Lecture{}, errors.New(T(im.lang, "import.invalid_subgroup", lecture.SubGroup))
+	}
+	return lecture, nil
+}
+
+// describes what an import would do, listing at most limit lectures
+func ImportReport(lectures []mdb.Lecture, errs []RowError, limit int, lang string) string {
+	var b strings.Builder
+	if len(errs) > 0 {
+		b.WriteString(Tn(lang, "import.errors", len(errs)) + "\n")
+		for i, err := range errs {
+			if i == limit {
+				b.WriteString(T(lang, "import.more", len(errs)-limit) + "\n")
+				break
+			}
+			b.WriteString(T(lang, "import.row", err.Row, err.Err) + "\n")
+		}
+		return b.String()
+	}
+	b.WriteString(Tn(lang, "import.ready", len(lectures)) + "\n")
+	for i, l := range lectures {
+		if i == limit {
+			b.WriteString(T(lang, "import.more", len(lectures)-limit) + "\n")
+			break
+		}
+		id := T(lang, "import.new")
+		if !l.ID.IsZero() {
+			id = l.ID.Hex()
+		}
+		b.WriteString(T(lang, "import.lecture", id, l.Week, l.Day, l.Time, l.Subject, l.Type, l.Room, l.SubGroup) + "\n")
+	}
+	return b.String()
+}
+
+// lists the rows clashing with stored lectures or with other rows, numbered
+// like the rows of the file. it returns how many rows clash
+func ConflictReport(db mdb.LectureStore, lectures []mdb.Lecture, numbers []int, limit int, lang string) (string, int, error) {
+	conflicts, err := mdb.ImportConflicts(db, lectures)
+	if err != nil {
+		return "", 0, err
+	}
+	var b strings.Builder
+	count := 0
+	for i, cs := range conflicts {
+		if len(cs) == 0 {
+			continue
+		}
+		count++
+		if count > limit {
+			continue
+		}
+		for _, c := range cs {
+			if c.Pending {
+				b.WriteString(T(lang, "import.clash_row", numbers[i], numbers[c.Index], conflictReasons(c, lang)) + "\n")
+				continue
+			}
+			b.WriteString(T(lang, "import.clash", numbers[i], conflictLine(c, lang)) + "\n")
+		}
+	}
+	if count > limit {
+		b.WriteString(T(lang, "import.more", count-limit) + "\n")
+	}
+	if count > 0 {
+		return Tn(lang, "import.clashes", count) + "\n" + b.String(), count, nil
+	}
+	return "", 0, nil
+}
+
+// runs "import [-group key] [-format csv|json|yaml] [-dry-run] [-force] file"
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	group := flags.String("group", defaultGroupKey(), "group of rows without a group column")
+	format := flags.String("format", "", "csv, json or yaml, detected from the file name by default")
+	dryRun := flags.Bool("dry-run", false, "only validate and preview the rows")
+	force := flags.Bool("force", false, "import rows clashing with other lectures")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import [-group key] [-format csv|json|yaml] [-dry-run] [-force] file")
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		var err error
+		if *format, err = FormatFromName(path); err != nil {
+			return err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rows, numbers, err := ParseRows(*format, data)
+	if err != nil {
+		return err
+	}
+	db, closeDb, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+	if err := prepareStore(db, defaultGroupKey()); err != nil {
+		return err
+	}
+	// the command line reports in english
+	lectures, errs := NewImporter(db, *group, false, "en").Lectures(rows, numbers)
+	fmt.Print(ImportReport(lectures, errs, len(rows), "en"))
+	if len(errs) > 0 {
+		return fmt.Errorf("import failed")
+	}
+	report, clashing, err := ConflictReport(db, lectures, numbers, len(rows), "en")
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+	if clashing > 0 && !*force && !*dryRun {
+		return fmt.Errorf("import failed, use -force to import clashing rows")
+	}
+	if *dryRun {
+		fmt.Println("dry run, nothing was imported")
+		return nil
+	}
+	// the audit log records imports from the command line as user 0
+	if err := mdb.ImportLecturesChecked(mdb.Audit(db, 0), lectures, *force); err != nil {
+		return err
+	}
+	fmt.Printf("imported %v lectures\n", len(lectures))
+	return nil
+}
+
+// asks the admin for the file to import. the import runs in a session like
+// the wizards, so it expires with them
+func StartImport(sessions *Sessions, chatID, userID int64, lang string, bot *tgbotapi.BotAPI) {
+	sessions.Save(mdb.Session{ChatID: chatID, UserID: userID, Kind: mdb.SessionImportLectures})
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "import.start", strings.Join(rowColumns, ", "))))
+}
+
+// validates the file uploaded into the group, shows a preview and imports
+// it once the admin confirms
+func HandleLectureImport(db mdb.Store, sessions *Sessions, group string, lang string, update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+	userID := update.Message.From.ID
+	chatID := update.Message.Chat.ID
+	text := strings.ToLower(strings.TrimSpace(update.Message.Text))
+
+	session, ok := sessions.Get(chatID, userID)
+	if !ok || session.Kind != mdb.SessionImportLectures {
+		return
+	}
+	if control(lang, text) == formCancel {
+		sessions.Delete(chatID, userID)
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "import.cancelled")))
+		return
+	}
+	if len(session.Lectures) > 0 {
+		if text != "confirm" && text != "force" || text == "confirm" && session.Clashing > 0 {
+			sessions.Save(session)
+			bot.Send(tgbotapi.NewMessage(chatID, confirmImport(session, lang)))
+			return
+		}
+		sessions.Delete(chatID, userID)
+		if err := mdb.ImportLecturesChecked(db, session.Lectures, text == "force"); err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, errorText(err, lang)))
+			return
+		}
+		log.Printf("user %v imported %v lectures", userID, len(session.Lectures))
+		bot.Send(tgbotapi.NewMessage(chatID, Tn(lang, "import.done", len(session.Lectures))))
+		return
+	}
+	doc := update.Message.Document
+	if doc == nil {
+		sessions.Save(session)
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "import.file")))
+		return
+	}
+	lectures, numbers, errs, err := previewImport(db, bot, group, lang, doc)
+	if err != nil {
+		sessions.Delete(chatID, userID)
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	report := ImportReport(lectures, errs, 30, lang)
+	if len(errs) > 0 || len(lectures) == 0 {
+		sessions.Delete(chatID, userID)
+		bot.Send(tgbotapi.NewMessage(chatID, report))
+		return
+	}
+	conflicts, clashing, err := ConflictReport(db, lectures, numbers, 30, lang)
+	if err != nil {
+		sessions.Delete(chatID, userID)
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	session.Lectures = lectures
+	session.Clashing = clashing
+	sessions.Save(session)
+	bot.Send(tgbotapi.NewMessage(chatID, report+conflicts+"\n"+confirmImport(session, lang)))
+}
+
+// asks the admin to confirm the import of the session
+func confirmImport(session mdb.Session, lang string) string {
+	if session.Clashing > 0 {
+		return T(lang, "import.force")
+	}
+	return T(lang, "import.confirm")
+}
+
+// downloads and validates an uploaded file without storing anything
+func previewImport(db mdb.Store, bot *tgbotapi.BotAPI, group string, lang string, doc *tgbotapi.Document) ([]mdb.Lecture, []int, []RowError, error) {
+	format, err := FormatFromName(doc.FileName)
+	if err != nil {
+		return nil, nil, nil, errors.New(T(lang, "import.format", filepath.Ext(doc.FileName)))
+	}
+	data, err := downloadFile(bot, doc.FileID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rows, numbers, err := ParseRows(format, data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	lectures, errs := NewImporter(db, group, true, lang).Lectures(rows, numbers)
+	return lectures, numbers, errs, nil
+}
+
+func downloadFile(bot *tgbotapi.BotAPI, fileID string) ([]byte, error) {
+	url, err := bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
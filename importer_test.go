@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestImporterLectures(t *testing.T) {
+	db := mdb.NewMemDb()
+	for _, group := range []string{"g", "h"} {
+		if err := prepareStore(db, group); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stored := mdb.Lecture{ID: primitive.NewObjectID(), Week: "1", Day: 1, Time: 1, Subject: "ОМО", Type: "ЛК", Room: "101", SubGroup: "0", Group: "g"}
+	foreign := mdb.Lecture{ID: primitive.NewObjectID(), Week: "1", Day: 1, Time: 1, Subject: "ОМО", Type: "ЛК", Room: "101", SubGroup: "0", Group: "h"}
+	for _, l := range []mdb.Lecture{stored, foreign} {
+		if err := db.InsertLecture(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	row := func(change func(r *LectureRow)) LectureRow {
+		r := LectureRow{Week: "2", Day: "Вторник", Period: "3", Subject: "Основы Машинного Обучения", Type: "ЛР", Room: "305", SubGroup: "1"}
+		change(&r)
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		row       LectureRow
+		onlyGroup bool
+		// the error the row gets, "" for none
+		err  string
+		want func(l mdb.Lecture) bool
+	}{
+		{"names", row(func(r *LectureRow) {}), true, "", func(l mdb.Lecture) bool {
+			return l.Group == "g" && l.Day == 2 && l.Time == 3 && l.Subject == "ОМО" && l.Lecturer == "Колодный В.Б" && l.ID.IsZero()
+		}},
+		{"numbers and keys", row(func(r *LectureRow) { r.Day, r.Period, r.Subject = "4", "9:55-11:35", "ТЭ" }), true, "", func(l mdb.Lecture) bool {
+			return l.Day == 4 && l.Time == 2 && l.Subject == "ТЭ"
+		}},
+		{"lecturer", row(func(r *LectureRow) { r.Lecturer = " Иванов И.И " }), true, "", func(l mdb.Lecture) bool {
+			return l.Lecturer == "Иванов И.И"
+		}},
+		{"whole group", row(func(r *LectureRow) { r.SubGroup = "" }), true, "", func(l mdb.Lecture) bool {
+			return l.SubGroup == "0"
+		}},
+		{"stored id", row(func(r *LectureRow) { r.ID = Cell(stored.ID.Hex()) }), true, "", func(l mdb.Lecture) bool {
+			return l.ID == stored.ID
+		}},
+		{"unknown id", row(func(r *LectureRow) { r.ID = Cell(primitive.NewObjectID().Hex()) }), true, "", func(l mdb.Lecture) bool {
+			return l.ID.IsZero()
+		}},
+		{"id of another group", row(func(r *LectureRow) { r.ID = Cell(foreign.ID.Hex()) }), true, "belongs to group h", nil},
+		{"other group", row(func(r *LectureRow) { r.Group = "h" }), true, "belongs to group h", nil},
+		{"other group of a bot-wide import", row(func(r *LectureRow) { r.Group = "h" }), false, "", func(l mdb.Lecture) bool {
+			return l.Group == "h"
+		}},
+		{"unknown group", row(func(r *LectureRow) { r.Group = "x" }), false, "not found", nil},
+		{"invalid id", row(func(r *LectureRow) { r.ID = "42" }), true, "invalid id", nil},
+		{"invalid week", row(func(r *LectureRow) { r.Week = "5" }), true, "invalid week", nil},
+		{"invalid day", row(func(r *LectureRow) { r.Day = "Воскресенье" }), true, "invalid day", nil},
+		{"invalid period", row(func(r *LectureRow) { r.Period = "7" }), true, "invalid period", nil},
+		{"unknown subject", row(func(r *LectureRow) { r.Subject = "Астрономия" }), true, "unknown subject", nil},
+		{"invalid type", row(func(r *LectureRow) { r.Type = "СЕМ" }), true, "invalid type", nil},
+		{"missing room", row(func(r *LectureRow) { r.Room = " " }), true, "missing room", nil},
+		{"invalid subgroup", row(func(r *LectureRow) { r.SubGroup = "3" }), true, "subgroup", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lectures, errs := NewImporter(db, "g", tt.onlyGroup, "en").Lectures([]LectureRow{tt.row}, []int{2})
+			if tt.err != "" {
+				if len(errs) != 1 || errs[0].Row != 2 || !strings.Contains(errs[0].Err.Error(), tt.err) {
+					t.Errorf("got errors %v, want one containing %q", errs, tt.err)
+				}
+				return
+			}
+			if len(errs) != 0 || len(lectures) != 1 {
+				t.Fatalf("got %v, errors %v", lectures, errs)
+			}
+			if !tt.want(lectures[0]) {
+				t.Errorf("got %+v", lectures[0])
+			}
+		})
+	}
+
+	// an id may be used once per import
+	rows := []LectureRow{
+		row(func(r *LectureRow) { r.ID = Cell(stored.ID.Hex()) }),
+		row(func(r *LectureRow) { r.ID = Cell(stored.ID.Hex()) }),
+	}
+	lectures, errs := NewImporter(db, "g", true, "en").Lectures(rows, []int{2, 3})
+	if len(lectures) != 1 || len(errs) != 1 || errs[0].Row != 3 {
+		t.Errorf("got %v, errors %v, want the second row rejected", lectures, errs)
+	}
+}
+
+func TestImportSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timetable.json")
+	db, err := mdb.NewFileDb(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lecture := mdb.Lecture{Week: "1", Day: 2, Time: 3, Subject: "ОМО", Type: "ЛК", Room: "101", SubGroup: "0", Group: "g"}
+	sessions := &Sessions{db: db, timeout: time.Hour}
+	sessions.Save(mdb.Session{ChatID: 1, UserID: 2, Kind: mdb.SessionImportLectures, Lectures: []mdb.Lecture{lecture}, Clashing: 1})
+
+	// the pending import outlives a restart of the bot
+	if db, err = mdb.NewFileDb(path); err != nil {
+		t.Fatal(err)
+	}
+	sessions = &Sessions{db: db, timeout: time.Hour}
+	s, ok := sessions.Get(1, 2)
+	if !ok || s.Kind != mdb.SessionImportLectures || len(s.Lectures) != 1 || s.Lectures[0] != lecture || s.Clashing != 1 {
+		t.Fatalf("got session %+v, %v", s, ok)
+	}
+	if _, ok := sessions.Get(3, 2); ok {
+		t.Error("the import shows up in another chat")
+	}
+	sessions.timeout = time.Nanosecond
+	if _, ok := sessions.Get(1, 2); ok {
+		t.Error("the import did not expire")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// how long a week rendered for inline queries is reused
+const inlineCacheTTL = 5 * time.Minute
+
+// WeekCache keeps the timetable of recently asked weeks per group and
+// subgroup, so typing an inline query does not hit the store on every key
+type WeekCache struct {
+	mu    sync.Mutex
+	weeks map[string]cachedWeek
+}
+
+type cachedWeek struct {
+	days    []DaySchedule
+	expires time.Time
+}
+
+func NewWeekCache() *WeekCache {
+	return &WeekCache{weeks: make(map[string]cachedWeek)}
+}
+
+// returns the timetable of the week starting on monday
+func (c *WeekCache) Get(db mdb.Store, opt mdb.Args, monday time.Time) ([]DaySchedule, error) {
+	key := fmt.Sprintf("%v/%v/%v", opt.StudyGroup, monday.Format(mdb.DateLayout), opt.Group)
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if week, ok := c.weeks[key]; ok && now.Before(week.expires) {
+		return week.days, nil
+	}
+	days, err := GetWeekSchedule(db, opt, monday)
+	if err != nil {
+		return nil, err
+	}
+	for k, week := range c.weeks {
+		if !now.Before(week.expires) {
+			delete(c.weeks, k)
+		}
+	}
+	c.weeks[key] = cachedWeek{days: days, expires: now.Add(inlineCacheTTL)}
+	return days, nil
+}
+
+// returns the timetable of the date, from the cached week when possible
+func (c *WeekCache) Day(db mdb.Store, opt mdb.Args, date time.Time) (DaySchedule, error) {
+	days, err := c.Get(db, opt, WeekStart(date))
+	if err != nil {
+		return DaySchedule{}, err
+	}
+	for _, day := range days {
+		if day.Date.Equal(DayStart(date)) {
+			return day, nil
+		}
+	}
+	// sundays are not part of the cached week
+	return GetDaySchedule(db, opt, date)
+}
+
+// answers "@bot <today|tomorrow|week|nextweek> [flags]" with rendered
+// timetables. the group is the one the user selected in a private chat
+func HandleInlineQuery(db mdb.Store, cache *WeekCache, query *tgbotapi.InlineQuery, defaultGroup string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(strings.ToLower(query.Query))
+	keyword := "today"
+	if len(fields) > 0 && !strings.HasPrefix(fields[0], "-") {
+		keyword, fields = fields[0], fields[1:]
+	}
+	prefs := UserPrefs(db, query.From.ID)
+	opt := ParseArgsWith(strings.Join(fields, " "), prefs.Args())
+	opt.Lang = prefs.Language
+	opt.StudyGroup = ChatGroup(db, query.From.ID, defaultGroup)
+
+	results, err := inlineResults(db, cache, keyword, opt)
+	if err != nil {
+		log.Printf("error: %v", err)
+		results = []interface{}{tgbotapi.NewInlineQueryResultArticle("error", T(opt.Lang, "inline.error"), fmt.Sprintf("error : %v", err))}
+	}
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     int(inlineCacheTTL.Seconds()),
+		IsPersonal:    true,
+	}
+	if _, err := bot.Request(answer); err != nil {
+		log.Printf("error answering inline query: %v", err)
+	}
+}
+
+func inlineResults(db mdb.Store, cache *WeekCache, keyword string, opt mdb.Args) ([]interface{}, error) {
+	cat, err := mdb.GetCatalog(db, opt.StudyGroup)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var days []DaySchedule
+	switch keyword {
+	case "today", "tomorrow":
+		date := now
+		if keyword == "tomorrow" {
+			date = date.AddDate(0, 0, 1)
+		}
+		day, err := cache.Day(db, opt, date)
+		if err != nil {
+			return nil, err
+		}
+		days = []DaySchedule{day}
+	case "week", "nextweek":
+		monday := WeekStart(now)
+		if keyword == "nextweek" {
+			monday = monday.AddDate(0, 0, 7)
+		}
+		if days, err = cache.Get(db, opt, monday); err != nil {
+			return nil, err
+		}
+	default:
+		return []interface{}{tgbotapi.NewInlineQueryResultArticle("help", "today, tomorrow, week, nextweek",
+			T(opt.Lang, "inline.help"))}, nil
+	}
+	var results []interface{}
+	for _, day := range days {
+		title := fmt.Sprintf("%v, %v", dayName(cat, day.Day, opt.Lang), day.Date.Format("02.01"))
+		var text, description string
+		if len(day.Lectures) == 0 {
+			text = T(opt.Lang, "day_free", title)
+			description = T(opt.Lang, "no_lectures")
+		} else {
+			text = FormatLectures(day.Lectures, title, opt, cat)
+			var subjects []string
+			for _, l := range day.Lectures {
+				subjects = append(subjects, l.Subject)
+			}
+			description = strings.Join(subjects, ", ")
+		}
+		id := fmt.Sprintf("%v/%v/%v/%v/%v", opt.StudyGroup, day.Date.Format(mdb.DateLayout), opt.Group, opt.Long, opt.Lang)
+		article := tgbotapi.NewInlineQueryResultArticleMarkdown(id, title, text)
+		article.Description = description
+		results = append(results, article)
+	}
+	return results, nil
+}
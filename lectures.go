@@ -0,0 +1,209 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// lectures on one page of the /lectures listing
+const lecturesPageSize = 10
+
+// parses "week=2 day=wed subject=ОМО sub=1" into a query of the group. the
+// errors are in the language of the user
+func ParseLectureQuery(args string, group string, cat mdb.Catalog, lang string) (mdb.LectureQuery, error) {
+	q := mdb.LectureQuery{Group: group}
+	for _, field := range strings.Fields(args) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return q, errors.New(T(lang, "list.invalid_filter", field, T(lang, "list.usage")))
+		}
+		switch strings.ToLower(key) {
+		case "week":
+			week, err := strconv.Atoi(value)
+			if err != nil || week < 1 || week > mdb.CycleWeeks {
+				return q, errors.New(T(lang, "list.invalid_week", value, mdb.CycleWeeks))
+			}
+			q.Week = week
+		case "day":
+			day, err := strconv.Atoi(value)
+			if err != nil {
+				var ok bool
+				if day, ok = parseWeekday(value); !ok {
+					return q, errors.New(T(lang, "list.invalid_day", value))
+				}
+			}
+			if _, ok := cat.Days[day]; !ok {
+				return q, errors.New(T(lang, "list.invalid_day", value))
+			}
+			q.Day = day
+		case "subject":
+			q.Subject = value
+			for key := range cat.Subjects {
+				if strings.EqualFold(key, value) {
+					q.Subject = key
+				}
+			}
+			if _, ok := cat.Subjects[q.Subject]; !ok {
+				return q, errors.New(T(lang, "list.unknown_subject", value))
+			}
+		case "sub":
+			if value != "1" && value != "2" {
+				return q, errors.New(T(lang, "list.invalid_subgroup", value))
+			}
+			q.SubGroup = value
+		default:
+			return q, errors.New(T(lang, "list.unknown_filter", key, T(lang, "list.usage")))
+		}
+	}
+	return q, nil
+}
+
+// the callback data of a page of the listing as
+// "lec:p:<page>:<week>:<day>:<subgroup>:<subject>"
+func lecturesPageData(q mdb.LectureQuery, page int) string {
+	return fmt.Sprintf("lec:p:%d:%d:%d:%v:%v", page, q.Week, q.Day, q.SubGroup, q.Subject)
+}
+
+// the query and the page of the callback data of a page
+func parseLecturesPageData(data string, group string) (mdb.LectureQuery, int, error) {
+	parts := strings.SplitN(data, ":", 7)
+	if len(parts) != 7 || parts[1] != "p" {
+		return mdb.LectureQuery{}, 0, fmt.Errorf("invalid lectures data %q", data)
+	}
+	page, err1 := strconv.Atoi(parts[2])
+	week, err2 := strconv.Atoi(parts[3])
+	day, err3 := strconv.Atoi(parts[4])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return mdb.LectureQuery{}, 0, fmt.Errorf("invalid lectures data %q", data)
+	}
+	return mdb.LectureQuery{Group: group, Week: week, Day: day, SubGroup: parts[5], Subject: parts[6]}, page, nil
+}
+
+// renders a page of the lectures matching the query with their IDs, a pair of
+// edit and delete buttons for each and buttons to the neighbouring pages
+func renderLectures(db mdb.Store, q mdb.LectureQuery, page int, lang string) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	cat, err := mdb.GetCatalog(db, q.Group)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	lectures, err := db.GetLectures(q.Filter())
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	if len(lectures) == 0 {
+		return T(lang, "list.none"), tgbotapi.NewInlineKeyboardMarkup(), nil
+	}
+	slices.SortStableFunc(lectures, func(a, b mdb.Lecture) int {
+		return cmp.Or(cmp.Compare(a.Day, b.Day), cmp.Compare(a.Time, b.Time), cmp.Compare(a.Week, b.Week), cmp.Compare(a.SubGroup, b.SubGroup))
+	})
+	pages := (len(lectures) + lecturesPageSize - 1) / lecturesPageSize
+	page = max(0, min(page, pages-1))
+	first := page * lecturesPageSize
+	last := min(first+lecturesPageSize, len(lectures))
+
+	text := T(lang, "list.header", first+1, last, len(lectures)) + "\n"
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, l := range lectures[first:last] {
+		n := first + i + 1
+		text += fmt.Sprintf("\n%d. `%v`\n`%v`\n", n, l.ID.Hex(), lectureLine(l, cat, lang))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✏️ %d", n), "lec:e:"+l.ID.Hex()),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🗑 %d", n), "lec:d:"+l.ID.Hex()),
+		))
+	}
+	var pager []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData("◀", lecturesPageData(q, page-1)))
+	}
+	if page < pages-1 {
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData("▶", lecturesPageData(q, page+1)))
+	}
+	if len(pager) > 0 {
+		text += "\n" + T(lang, "list.page", page+1, pages)
+		rows = append(rows, pager)
+	}
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// one line with every field of the lecture in the language
+func lectureLine(l mdb.Lecture, cat mdb.Catalog, lang string) string {
+	week := T(lang, "week", l.Week)
+	if l.Week == "0" {
+		week = T(lang, "list.every_week")
+	}
+	return fmt.Sprintf("%v | %v | %v | %v | %v | %v | %v", week, dayName(cat, l.Day, lang), cat.Periods[l.Time],
+		l.Subject, l.Type, l.Room, subGroupName(l.SubGroup, lang))
+}
+
+// handles "/lectures [filters]"
+func ListLectures(db mdb.Store, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	q, err := ParseLectureQuery(args, group, cat, lang)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+	text, keyboard, err := renderLectures(db, q, 0, lang)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	if _, err := bot.Send(msg); err != nil {
+		log.Printf("sending error : %v", err)
+	}
+}
+
+// turns the page of the listing, or starts the edit or delete wizard on the
+// lecture of the pressed button
+func HandleLecturesCallback(db mdb.Store, sessions *Sessions, query *tgbotapi.CallbackQuery, group string, lang string, bot *tgbotapi.BotAPI) {
+	chatID := query.Message.Chat.ID
+	action, id, _ := strings.Cut(strings.TrimPrefix(query.Data, "lec:"), ":")
+	switch action {
+	case "p":
+		q, page, err := parseLecturesPageData(query.Data, group)
+		if err != nil {
+			log.Printf("error: %v", err)
+			return
+		}
+		text, keyboard, err := renderLectures(db, q, page, lang)
+		if err != nil {
+			bot.Request(tgbotapi.NewCallback(query.ID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID, text, keyboard)
+		edit.ParseMode = tgbotapi.ModeMarkdown
+		if _, err := bot.Request(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+			log.Printf("error editing message: %v", err)
+		}
+	case "e", "d":
+		kind := mdb.SessionEditLecture
+		if action == "d" {
+			kind = mdb.SessionDeleteLecture
+		}
+		lecture, err := db.GetLecture(id)
+		if err != nil {
+			bot.Request(tgbotapi.NewCallback(query.ID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		StartLectureWizard(db, sessions, group, kind, lecture, chatID, query.From.ID, lang, bot)
+	default:
+		log.Printf("invalid lectures data %q", query.Data)
+	}
+}
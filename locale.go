@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// the language of the messages when nobody chose one. its catalog is
+// complete, the others fall back to it
+const defaultLanguage = "ru"
+
+// message catalogs by language
+var messageCatalogs = map[string]map[string]string{
+	"ru": messagesRu,
+	"en": messagesEn,
+	"be": messagesBe,
+}
+
+// languages the users can switch between, in toggle order
+var languages = []string{"ru", "en", "be"}
+
+// the language itself if there is a catalog for it, else the default
+func knownLanguage(lang string) string {
+	if _, ok := messageCatalogs[lang]; ok {
+		return lang
+	}
+	return defaultLanguage
+}
+
+// T returns the message of the language formatted with args
+func T(lang, key string, args ...any) string {
+	lang = knownLanguage(lang)
+	message, ok := messageCatalogs[lang][key]
+	if !ok {
+		message, ok = messageCatalogs[defaultLanguage][key]
+	}
+	if !ok {
+		log.Printf("error: no message %q", key)
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Tn returns the plural form of the message for n, "<key>.one", "<key>.few"
+// or "<key>.many", formatted with n and args
+func Tn(lang, key string, n int, args ...any) string {
+	return T(lang, key+"."+pluralForm(lang, n), append([]any{n}, args...)...)
+}
+
+// the plural category of n. english only knows one and many, russian and
+// belarusian pick few for 2-4 except 12-14
+func pluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch knownLanguage(lang) {
+	case "ru", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "many"
+	}
+}
+
+// the language of messages in the chat: the one chosen for the chat with
+// /language, else the one the user chose in /settings. scheduled messages
+// pass the chat as the user, which finds the settings of private chats
+func Language(db mdb.Store, chatID, userID int64) string {
+	chat, err := db.GetChat(chatID)
+	if err != nil && !errors.Is(err, mdb.ErrNotFound) {
+		log.Printf("error: %v", err)
+	}
+	if chat.Language != "" {
+		return chat.Language
+	}
+	return UserPrefs(db, userID).Language
+}
+
+// handles "/language <ru|en|be>". in private chats it changes the user's
+// setting, in group chats the language of the whole chat
+func HandleLanguage(db mdb.Store, chatID, userID int64, lang string, args string, bot *tgbotapi.BotAPI) {
+	code := strings.ToLower(strings.TrimSpace(args))
+	if !slices.Contains(languages, code) {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "language.usage", lang)))
+		return
+	}
+	var err error
+	if chatID == userID {
+		p := UserPrefs(db, userID)
+		p.Language = code
+		err = db.SavePrefs(p)
+	} else {
+		var chat mdb.Chat
+		chat, err = db.GetChat(chatID)
+		if err == nil {
+			chat.Language = code
+			err = db.SaveChat(chat)
+		}
+	}
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, T(code, "language.set", code)))
+}
+
+// the name of the weekday. russian names come from the managed catalog,
+// other languages and sunday, which is not part of it, from the messages
+func dayName(cat mdb.Catalog, day int, lang string) string {
+	if name, ok := cat.Days[day]; ok && knownLanguage(lang) == defaultLanguage {
+		return name
+	}
+	return T(lang, fmt.Sprintf("day.%d", day))
+}
+
+// the full name of the subject in the language, if it was translated
+func subjectName(cat mdb.Catalog, key string, lang string) string {
+	s, ok := cat.Subjects[key]
+	if !ok {
+		return key
+	}
+	if name := s.Names[lang]; name != "" {
+		return name
+	}
+	return s.Name
+}
+
+// describes the subgroup flag for users
+func subGroupName(subGroup string, lang string) string {
+	if subGroup == "" || subGroup == "0" {
+		return T(lang, "subgroup.all")
+	}
+	return T(lang, "subgroup.one", subGroup)
+}
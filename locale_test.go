@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralForm(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"ru", 0, "many"},
+		{"ru", 1, "one"},
+		{"ru", 2, "few"},
+		{"ru", 4, "few"},
+		{"ru", 5, "many"},
+		{"ru", 11, "many"},
+		{"ru", 12, "many"},
+		{"ru", 14, "many"},
+		{"ru", 21, "one"},
+		{"ru", 22, "few"},
+		{"ru", 111, "many"},
+		{"ru", 112, "many"},
+		{"ru", -3, "few"},
+		{"be", 1, "one"},
+		{"be", 3, "few"},
+		{"be", 25, "many"},
+		{"en", 0, "many"},
+		{"en", 1, "one"},
+		{"en", 2, "many"},
+		{"en", 21, "many"},
+		// unknown languages use the default one
+		{"de", 2, "few"},
+	}
+	for _, tt := range tests {
+		if got := pluralForm(tt.lang, tt.n); got != tt.want {
+			t.Errorf("pluralForm(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTn(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"ru", 1, "1 минуту"},
+		{"ru", 3, "3 минуты"},
+		{"ru", 15, "15 минут"},
+		{"en", 1, "1 minute"},
+		{"en", 15, "15 minutes"},
+	}
+	for _, tt := range tests {
+		if got := Tn(tt.lang, "minutes", tt.n); got != tt.want {
+			t.Errorf("Tn(%q, minutes, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+// every message of the other languages has to exist in the default one,
+// which they fall back to, and plural messages need every form of their
+// language
+func TestCatalogs(t *testing.T) {
+	for lang, messages := range messageCatalogs {
+		forms := []string{"many"}
+		if lang != "en" {
+			forms = append(forms, "few")
+		}
+		for key := range messages {
+			if _, ok := messageCatalogs[defaultLanguage][key]; !ok {
+				t.Errorf("message %q of %v is missing in %v", key, lang, defaultLanguage)
+			}
+			// "subgroup.one" is not a plural, plurals have a many form in
+			// the default language
+			base, ok := strings.CutSuffix(key, ".one")
+			if _, plural := messageCatalogs[defaultLanguage][base+".many"]; !ok || !plural {
+				continue
+			}
+			for _, form := range forms {
+				if _, ok := messages[base+"."+form]; !ok {
+					t.Errorf("message %q of %v has no %v form", base, lang, form)
+				}
+			}
+		}
+	}
+}
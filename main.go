@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/RemyJohnny/timetable/mdb"
@@ -14,6 +15,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	/* err := godotenv.Load()
 	if err != nil {
 		log.Fatal("Error loading .env file")
@@ -24,20 +33,15 @@ func main() {
 	}
 	log.SetOutput(logFile)
 
-	clientOptions := options.Client().ApplyURI(os.Getenv("TIMETABLE_MONGODB_STRING"))
-	client, err := mongo.Connect(context.TODO(), clientOptions)
+	db, closeDb, err := openStore()
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer closeDb()
 
-	defer func() {
-		if err := client.Disconnect(context.TODO()); err != nil {
-			log.Fatal(err)
-		}
-	}()
-
-	db := mdb.Db{
-		LectureCollection: client.Database("timetable").Collection("lecture"),
+	defaultGroup := defaultGroupKey()
+	if err := prepareStore(db, defaultGroup); err != nil {
+		log.Fatal(err)
 	}
 
 	// Initialize the bot with your token
@@ -46,23 +50,42 @@ func main() {
 		log.Fatal(err)
 	}
 
-	admins := strings.Split(os.Getenv("TIMETABLE_ADMINS_USERID"), "|")
+	// TIMETABLE_ADMINS_USERID only seeds the first owners, roles are granted
+	// from the bot after that
+	if err := prepareRoles(db, os.Getenv("TIMETABLE_ADMINS_USERID")); err != nil {
+		log.Fatal(err)
+	}
 
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
+	StartHTTPServer(db, defaultGroup)
+
+	sessions := NewSessions(db)
+
+	scheduler := NewScheduler(db, bot)
+	scheduler.Add(scheduler.remind)
+	scheduler.Add(scheduler.digest)
+	scheduler.Add(sessions.cleanup)
+	scheduler.Start()
+
 	// Set up an update config to listen for new messages
 	updateConfig := tgbotapi.NewUpdate(0)
 	updateConfig.Timeout = 60
 
 	updates := bot.GetUpdatesChan(updateConfig)
 
-	var lectureInput = make(map[int64]mdb.Lecture)
-	var lectureUpdate = make(map[int64]UpdateLecture)
-	var lectureDelete = make(map[int64]string)
-
 	var mm = NewMessageManager(bot)
+	var weekCache = NewWeekCache()
 
 	for update := range updates {
+		if update.InlineQuery != nil {
+			HandleInlineQuery(db, weekCache, update.InlineQuery, defaultGroup, bot)
+			continue
+		}
+		if update.CallbackQuery != nil {
+			HandleCallbackQuery(db, sessions, update.CallbackQuery, defaultGroup, bot)
+			continue
+		}
 		if update.Message == nil { // ignore non-messages
 			continue
 		}
@@ -73,56 +96,251 @@ func main() {
 		chatID := update.Message.Chat.ID
 		text := strings.ToLower(update.Message.Text)
 		command := update.Message.Command()
+		group := ChatGroup(db, chatID, defaultGroup)
+		RegisterChat(db, chatID, group)
+		prefs := UserPrefs(db, userID)
+		msgs := userMessages(prefs, mm)
+		lang := Language(db, chatID, userID)
+		// admin changes go through the audit log
+		audited := mdb.Audit(db, userID)
+		if !Authorize(db, group, chatID, userID, command) {
+			continue
+		}
 		switch command {
+		case "group":
+			SelectGroup(db, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "addgroup":
+			AddGroup(audited, chatID, lang, update.Message.CommandArguments(), bot)
+		case "grant":
+			HandleGrant(audited, chatID, userID, group, lang, update.Message.CommandArguments(), bot)
+		case "revoke":
+			HandleRevoke(audited, chatID, userID, lang, update.Message.CommandArguments(), bot)
+		case "subjects":
+			SendSubjects(db, chatID, group, lang, bot)
+		case "addsubject":
+			AddSubject(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "subjectname":
+			TranslateSubject(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "editsubject":
+			EditSubject(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "deletesubject":
+			DeleteSubject(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "setperiods":
+			SetPeriods(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "cancel":
+			CancelLecture(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "changeroom", "changelecturer":
+			ChangeLecture(audited, chatID, group, command, lang, update.Message.CommandArguments(), bot)
+		case "addoneoff":
+			AddOneOff(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "overrides":
+			SendOverrides(db, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "deleteoverride":
+			DeleteOverride(audited, chatID, group, lang, update.Message.CommandArguments(), bot)
 		case "addlecture":
-			if Auth(admins, strconv.FormatInt(userID, 10)) {
-				lectureInput[userID] = mdb.Lecture{}
-				msg := tgbotapi.NewMessage(update.Message.Chat.ID, "select the week for the lecture ( 0 for all)")
-				msg.ReplyMarkup = GenMenu(mdb.Weeks, false)
-				bot.Send(msg)
-			}
+			StartWizard(db, sessions, group, mdb.SessionAddLecture, chatID, userID, lang, bot)
 		case "editlecture":
-			if Auth(admins, strconv.FormatInt(userID, 10)) {
-				lectureUpdate[userID] = UpdateLecture{}
-				msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Enter the ID of the lecture you want to edit: ")
-				bot.Send(msg)
-			}
+			StartWizard(db, sessions, group, mdb.SessionEditLecture, chatID, userID, lang, bot)
+		case "lectures":
+			ListLectures(db, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "history":
+			HandleHistory(db, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "undo":
+			HandleUndo(audited, chatID, userID, group, lang, update.Message.CommandArguments(), bot)
+		case "import":
+			StartImport(sessions, chatID, userID, lang, bot)
+		case "apitoken":
+			HandleAPIToken(db, chatID, userID, group, lang, update.Message.CommandArguments(), bot)
+		case "export":
+			SendExport(db, chatID, group, update.Message.CommandArguments(), bot)
 		case "deletelecture":
-			if Auth(admins, strconv.FormatInt(userID, 10)) {
-				lectureDelete[userID] = ""
-				msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Enter the ID of the lecture you want to delete: ")
-				bot.Send(msg)
-			}
+			StartWizard(db, sessions, group, mdb.SessionDeleteLecture, chatID, userID, lang, bot)
 		case "today":
 			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/today")
-			arg := ParseArgs(argStr)
-			sendToday(&db, chatID, bot, arg, false, mm)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			sendToday(db, chatID, bot, arg, 0, msgs)
 		case "tomorrow":
 			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/tomorrow")
-			arg := ParseArgs(argStr)
-			sendToday(&db, chatID, bot, arg, true, mm)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			sendToday(db, chatID, bot, arg, 1, msgs)
 		case "thisweek":
 			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/thisweek")
-			arg := ParseArgs(argStr)
-			SendWeek(&db, chatID, bot, arg, false, mm)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			SendWeek(db, chatID, bot, arg, 0, msgs)
 		case "nextweek":
 			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/nextweek")
-			arg := ParseArgs(argStr)
-			SendWeek(&db, chatID, bot, arg, true, mm)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			SendWeek(db, chatID, bot, arg, 1, msgs)
+		case "week", "weeks":
+			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/"+command)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			if command == "week" {
+				SendCycleWeek(db, chatID, bot, arg, update.Message.CommandArguments(), msgs)
+			} else {
+				SendWeeks(db, chatID, bot, arg, update.Message.CommandArguments(), msgs)
+			}
+		case "whichweek":
+			SendWhichWeek(chatID, lang, bot)
+		case "now", "next":
+			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/"+command)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			SendNow(db, chatID, bot, arg, command == "next", msgs)
+		case "date", "day":
+			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/"+command)
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			RememberSubGroup(db, chatID, arg.Group)
+			SendDay(db, chatID, bot, arg, update.Message.CommandArguments(), command == "day", msgs)
+		case "ics":
+			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/ics")
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			SendICS(db, chatID, bot, arg)
+		case "remind":
+			HandleRemind(db, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "digest":
+			HandleDigest(db, chatID, group, lang, update.Message.CommandArguments(), bot)
+		case "settings":
+			HandleSettings(db, chatID, userID, bot)
+		case "language":
+			HandleLanguage(db, chatID, userID, lang, update.Message.CommandArguments(), bot)
+		case "notify":
+			HandleNotify(db, chatID, lang, update.Message.CommandArguments(), bot)
+		case "calendar":
+			argStr, _ := strings.CutPrefix(strings.TrimSpace(text), "/calendar")
+			arg := ParseArgsWith(argStr, prefs.Args())
+			arg.StudyGroup = group
+			arg.Lang = lang
+			HandleCalendar(db, chatID, userID, update.Message.CommandArguments(), arg, bot)
 		case "help":
-			helpTxt := "*/today* `команда возвращает расписание на сегодня`\n\n*/tomorrow* `команда возвращает расписание на завтра`\n\n*/thisweek* `команда возвращает расписание на текущую неделю`\n\n*/nextweek* `команда возвращает расписание на следующую неделю`\n\n\n"
-			flagTxt := "*-l*  : `Отображает полное имя предмета и имя преподавателя. имя предмета по умолчанию сокращается`\n\n*-1*  : `возвращает расписание для подгруппы 1 . по умолчанию` \n\n*-2*  : `возвращает расписание для подгруппы 2 `\n\n*-all*  : `возвращает расписание для всей подгруппы`\n\n"
-			expTxt := "*-Пример-*\n    /сегодня -l -2\n`Возвращает расписание на сегодня и для подгруппы 2 с именем лектора и полным именем предмета.`"
-			msg := tgbotapi.NewMessage(update.Message.Chat.ID, helpTxt+"`Добавьте флаги в команду, чтобы изменить, как и что возвращается. флаги:`\n"+flagTxt+expTxt)
+			msg := tgbotapi.NewMessage(update.Message.Chat.ID, T(lang, "help", bot.Self.UserName)+T(lang, "help.flags")+T(lang, "help.example"))
 			msg.ParseMode = tgbotapi.ModeMarkdown
 			_, err := bot.Send(msg)
 			if err != nil {
 				log.Printf("error: %v", err)
 			}
 		default:
-			HandleLectureInput(&db, lectureInput, &update, bot)
-			HandleLectureUpdate(&db, lectureUpdate, &update, bot)
-			HandleLectureDelete(&db, lectureDelete, &update, bot)
+			HandleForm(audited, sessions, group, lang, &update, bot)
+			HandleLectureImport(audited, sessions, group, lang, &update, bot)
 		}
 	}
 }
+
+// opens the storage backend selected by TIMETABLE_STORAGE:
+// "mongo" (default), "memory" or "file"
+func openStore() (mdb.Store, func(), error) {
+	switch os.Getenv("TIMETABLE_STORAGE") {
+	case "", "mongo":
+		clientOptions := options.Client().ApplyURI(os.Getenv("TIMETABLE_MONGODB_STRING"))
+		client, err := mongo.Connect(context.TODO(), clientOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		database := client.Database("timetable")
+		db := &mdb.Db{
+			LectureCollection:      database.Collection("lecture"),
+			GroupCollection:        database.Collection("group"),
+			ChatCollection:         database.Collection("chat"),
+			SubjectCollection:      database.Collection("subject"),
+			PeriodCollection:       database.Collection("period"),
+			DayCollection:          database.Collection("day"),
+			TypeCollection:         database.Collection("type"),
+			OverrideCollection:     database.Collection("override"),
+			TokenCollection:        database.Collection("token"),
+			SubscriptionCollection: database.Collection("subscription"),
+			SessionCollection:      database.Collection("session"),
+			PrefsCollection:        database.Collection("prefs"),
+			AuditCollection:        database.Collection("audit"),
+			RoleCollection:         database.Collection("role"),
+		}
+		return db, func() {
+			if err := client.Disconnect(context.TODO()); err != nil {
+				log.Fatal(err)
+			}
+		}, nil
+	case "memory":
+		return mdb.NewMemDb(), func() {}, nil
+	case "file":
+		path := os.Getenv("TIMETABLE_STORAGE_FILE")
+		if path == "" {
+			path = "timetable.json"
+		}
+		db, err := mdb.NewFileDb(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q", os.Getenv("TIMETABLE_STORAGE"))
+	}
+}
+
+// makes sure the default group and the catalog exist. the changes are
+// recorded in the audit log as made by user 0
+func prepareStore(db mdb.Store, defaultGroup string) error {
+	db = mdb.Audit(db, 0)
+	if err := ensureGroup(db, defaultGroup); err != nil {
+		return err
+	}
+	return mdb.SeedCatalog(db, defaultGroup)
+}
+
+// creates the group if it does not exist yet and hands it every lecture
+// stored before the bot knew about groups
+func ensureGroup(db mdb.Store, key string) error {
+	_, err := db.GetGroup(key)
+	if errors.Is(err, mdb.ErrNotFound) {
+		err = db.InsertGroup(mdb.Group{Key: key, Name: key})
+	}
+	if err != nil {
+		return err
+	}
+	count, err := db.AssignLectureGroup(key)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("assigned %d lectures to group %s", count, key)
+	}
+	return nil
+}
+
+// the group of chats that have not selected one
+func defaultGroupKey() string {
+	if group := os.Getenv("TIMETABLE_DEFAULT_GROUP"); group != "" {
+		return group
+	}
+	return "default"
+}
+
+// runs the command line subcommands
+func runCommand(name string, args []string) error {
+	switch name {
+	case "import":
+		return runImport(args)
+	case "export":
+		return runExport(args)
+	default:
+		return fmt.Errorf("unknown command %q, available: import, export", name)
+	}
+}
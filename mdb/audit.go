@@ -0,0 +1,509 @@
+package mdb
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changes an audit entry can record
+const (
+	AuditInsert = "insert"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditDoc is a document of an audit entry, at most one field is set
+type AuditDoc struct {
+	Lecture  *Lecture  `bson:"lecture,omitempty" json:"lecture,omitempty"`
+	Subject  *Subject  `bson:"subject,omitempty" json:"subject,omitempty"`
+	Override *Override `bson:"override,omitempty" json:"override,omitempty"`
+	Group    *Group    `bson:"group,omitempty" json:"group,omitempty"`
+	Grant    *Grant    `bson:"grant,omitempty" json:"grant,omitempty"`
+	// the whole catalog lists of the bot
+	Periods map[int]Period `bson:"periods,omitempty" json:"periods,omitempty"`
+	Days    map[int]string `bson:"days,omitempty" json:"days,omitempty"`
+	Types   map[string]int `bson:"types,omitempty" json:"types,omitempty"`
+	// lectures changed together, like the ones handed to a group
+	Lectures []Lecture `bson:"lectures,omitempty" json:"lectures,omitempty"`
+}
+
+// AuditEntry records one change made to the stored data
+type AuditEntry struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	UserID int64              `bson:"user_id"`
+	Time   time.Time          `bson:"time"`
+	Action string             `bson:"action"`
+	Group  string             `bson:"group"`
+	// the ID of the lecture, the override or the grant, the key of the
+	// subject or the group, or the name of the catalog list. changes of the
+	// whole bot have no group
+	Key string `bson:"key"`
+	// the document before and after the change, empty on insert and delete
+	Before AuditDoc `bson:"before"`
+	After  AuditDoc `bson:"after"`
+	// the entry a change made by undo reverts
+	Reverts primitive.ObjectID `bson:"reverts,omitempty"`
+	Undone  bool               `bson:"undone"`
+}
+
+// the kind of document the entry is about
+func (e AuditEntry) Kind() string {
+	doc := e.After
+	if e.Action == AuditDelete {
+		doc = e.Before
+	}
+	switch {
+	case doc.Lecture != nil:
+		return "lecture"
+	case doc.Subject != nil:
+		return "subject"
+	case doc.Override != nil:
+		return "override"
+	case doc.Group != nil:
+		return "group"
+	case doc.Grant != nil:
+		return "grant"
+	case doc.Periods != nil:
+		return "periods"
+	case doc.Days != nil:
+		return "days"
+	case doc.Types != nil:
+		return "types"
+	case doc.Lectures != nil:
+		return "lectures"
+	default:
+		return "unknown"
+	}
+}
+
+func (d *Db) InsertAudit(e AuditEntry) error {
+	if _, err := d.AuditCollection.InsertOne(context.TODO(), e); err != nil {
+		return fmt.Errorf("error inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetAudit(filter bson.M, limit int) ([]AuditEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := d.AuditCollection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+	defer cursor.Close(context.TODO())
+	var entries []AuditEntry
+	if err := cursor.All(context.TODO(), &entries); err != nil {
+		return nil, fmt.Errorf("error decoding audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (d *Db) SetAuditUndone(id primitive.ObjectID) error {
+	result, err := d.AuditCollection.UpdateByID(context.TODO(), id, bson.M{"$set": bson.M{"undone": true}})
+	if err != nil {
+		return fmt.Errorf("error updating audit entry: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("audit entry %v: %w", id.Hex(), ErrNotFound)
+	}
+	return nil
+}
+
+func (m *MemDb) InsertAudit(e AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e.ID.IsZero() {
+		e.ID = primitive.NewObjectID()
+	}
+	if err := put(m, m.audit, e.ID, e); err != nil {
+		return fmt.Errorf("error inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetAudit(filter bson.M, limit int) ([]AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var entries []AuditEntry
+	for _, e := range m.audit {
+		doc, err := toDoc(e)
+		if err != nil {
+			return nil, fmt.Errorf("error getting audit entries: %w", err)
+		}
+		if matches(doc, filter) {
+			entries = append(entries, e)
+		}
+	}
+	sortAudit(entries)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (m *MemDb) SetAuditUndone(id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.audit[id]
+	if !ok {
+		return fmt.Errorf("audit entry %v: %w", id.Hex(), ErrNotFound)
+	}
+	e.Undone = true
+	if err := put(m, m.audit, id, e); err != nil {
+		return fmt.Errorf("error updating audit entry: %w", err)
+	}
+	return nil
+}
+
+// orders audit entries newest first
+func sortAudit(entries []AuditEntry) {
+	slices.SortFunc(entries, func(a, b AuditEntry) int {
+		return cmp.Or(b.Time.Compare(a.Time), bytes.Compare(b.ID[:], a.ID[:]))
+	})
+}
+
+// Audited is a store that records every change made through it in the audit
+// log in the name of a user. recording is best
+// effort, a change that was made is never reported as failed
+type Audited struct {
+	Store
+	UserID int64
+	// set while undo reverts the entry
+	reverts primitive.ObjectID
+}
+
+// returns the store recording the changes of the user
+func Audit(db Store, userID int64) *Audited {
+	if a, ok := db.(*Audited); ok {
+		db = a.Store
+	}
+	return &Audited{Store: db, UserID: userID}
+}
+
+func (a *Audited) record(action, group, key string, before, after AuditDoc) {
+	e := AuditEntry{
+		ID:      primitive.NewObjectID(),
+		UserID:  a.UserID,
+		Time:    time.Now(),
+		Action:  action,
+		Group:   group,
+		Key:     key,
+		Before:  before,
+		After:   after,
+		Reverts: a.reverts,
+	}
+	if err := a.Store.InsertAudit(e); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
+
+func (a *Audited) InsertLecture(lecture Lecture) error {
+	// the ID is chosen here so the entry can name the lecture
+	if lecture.ID.IsZero() {
+		lecture.ID = primitive.NewObjectID()
+	}
+	if err := a.Store.InsertLecture(lecture); err != nil {
+		return err
+	}
+	a.record(AuditInsert, lecture.Group, lecture.ID.Hex(), AuditDoc{}, AuditDoc{Lecture: &lecture})
+	return nil
+}
+
+func (a *Audited) UpdateLecture(ID primitive.ObjectID, lecture Lecture) error {
+	before, err := a.Store.GetLecture(ID.Hex())
+	if err != nil {
+		return err
+	}
+	if err := a.Store.UpdateLecture(ID, lecture); err != nil {
+		return err
+	}
+	lecture.ID = ID
+	a.record(AuditUpdate, before.Group, ID.Hex(), AuditDoc{Lecture: &before}, AuditDoc{Lecture: &lecture})
+	return nil
+}
+
+func (a *Audited) DeleteLecture(lectureID string) error {
+	before, err := a.Store.GetLecture(lectureID)
+	if err != nil {
+		return err
+	}
+	if err := a.Store.DeleteLecture(lectureID); err != nil {
+		return err
+	}
+	a.record(AuditDelete, before.Group, lectureID, AuditDoc{Lecture: &before}, AuditDoc{})
+	return nil
+}
+
+func (a *Audited) ImportLectures(lectures []Lecture) error {
+	ids := make([]primitive.ObjectID, 0, len(lectures))
+	for i := range lectures {
+		if lectures[i].ID.IsZero() {
+			lectures[i].ID = primitive.NewObjectID()
+		}
+		ids = append(ids, lectures[i].ID)
+	}
+	stored, err := a.Store.GetLectures(bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	if err := a.Store.ImportLectures(lectures); err != nil {
+		return err
+	}
+	previous := make(map[primitive.ObjectID]Lecture, len(stored))
+	for _, lecture := range stored {
+		previous[lecture.ID] = lecture
+	}
+	for _, lecture := range lectures {
+		if before, ok := previous[lecture.ID]; ok {
+			a.record(AuditUpdate, lecture.Group, lecture.ID.Hex(), AuditDoc{Lecture: &before}, AuditDoc{Lecture: &lecture})
+		} else {
+			a.record(AuditInsert, lecture.Group, lecture.ID.Hex(), AuditDoc{}, AuditDoc{Lecture: &lecture})
+		}
+	}
+	return nil
+}
+
+func (a *Audited) InsertSubject(subject Subject) error {
+	if err := a.Store.InsertSubject(subject); err != nil {
+		return err
+	}
+	a.record(AuditInsert, subject.Group, subject.Key, AuditDoc{}, AuditDoc{Subject: &subject})
+	return nil
+}
+
+func (a *Audited) UpdateSubject(subject Subject) error {
+	subjects, err := a.Store.GetSubjects(subject.Group)
+	if err != nil {
+		return err
+	}
+	before, ok := subjects[subject.Key]
+	if !ok {
+		return fmt.Errorf("subject [ %v ] %w", subject.Key, ErrNotFound)
+	}
+	if err := a.Store.UpdateSubject(subject); err != nil {
+		return err
+	}
+	a.record(AuditUpdate, subject.Group, subject.Key, AuditDoc{Subject: &before}, AuditDoc{Subject: &subject})
+	return nil
+}
+
+func (a *Audited) DeleteSubject(group, key string) error {
+	subjects, err := a.Store.GetSubjects(group)
+	if err != nil {
+		return err
+	}
+	before, ok := subjects[key]
+	if !ok {
+		return fmt.Errorf("subject [ %v ] %w", key, ErrNotFound)
+	}
+	if err := a.Store.DeleteSubject(group, key); err != nil {
+		return err
+	}
+	a.record(AuditDelete, group, key, AuditDoc{Subject: &before}, AuditDoc{})
+	return nil
+}
+
+func (a *Audited) InsertOverride(o Override) error {
+	if o.ID.IsZero() {
+		o.ID = primitive.NewObjectID()
+	}
+	if err := a.Store.InsertOverride(o); err != nil {
+		return err
+	}
+	a.record(AuditInsert, o.Group, o.ID.Hex(), AuditDoc{}, AuditDoc{Override: &o})
+	return nil
+}
+
+func (a *Audited) DeleteOverride(overrideID string) error {
+	ID, err := primitive.ObjectIDFromHex(overrideID)
+	if err != nil {
+		return fmt.Errorf("error converting ObjectID from Hex: %w", err)
+	}
+	stored, err := a.Store.GetOverrides(bson.M{"_id": ID})
+	if err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return fmt.Errorf("override [ %s ] not found", overrideID)
+	}
+	if err := a.Store.DeleteOverride(overrideID); err != nil {
+		return err
+	}
+	a.record(AuditDelete, stored[0].Group, overrideID, AuditDoc{Override: &stored[0]}, AuditDoc{})
+	return nil
+}
+
+func (a *Audited) InsertGroup(group Group) error {
+	if err := a.Store.InsertGroup(group); err != nil {
+		return err
+	}
+	a.record(AuditInsert, group.Key, group.Key, AuditDoc{}, AuditDoc{Group: &group})
+	return nil
+}
+
+func (a *Audited) UpdateGroup(group Group) error {
+	before, err := a.Store.GetGroup(group.Key)
+	if err != nil {
+		return err
+	}
+	if err := a.Store.UpdateGroup(group); err != nil {
+		return err
+	}
+	a.record(AuditUpdate, group.Key, group.Key, AuditDoc{Group: &before}, AuditDoc{Group: &group})
+	return nil
+}
+
+func (a *Audited) AssignLectureGroup(group string) (int, error) {
+	before, err := a.Store.GetLectures(bson.M{"$or": []bson.M{
+		{"group": bson.M{"$exists": false}},
+		{"group": ""},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	count, err := a.Store.AssignLectureGroup(group)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	after := make([]Lecture, len(before))
+	for i, lecture := range before {
+		lecture.Group = group
+		after[i] = lecture
+	}
+	a.record(AuditUpdate, group, "lectures", AuditDoc{Lectures: before}, AuditDoc{Lectures: after})
+	return count, nil
+}
+
+func (a *Audited) SetPeriods(periods map[int]Period) error {
+	before, err := a.Store.GetPeriods()
+	if err != nil {
+		return err
+	}
+	if err := a.Store.SetPeriods(periods); err != nil {
+		return err
+	}
+	a.record(AuditUpdate, "", "periods", AuditDoc{Periods: before}, AuditDoc{Periods: periods})
+	return nil
+}
+
+func (a *Audited) SetDays(days map[int]string) error {
+	before, err := a.Store.GetDays()
+	if err != nil {
+		return err
+	}
+	if err := a.Store.SetDays(days); err != nil {
+		return err
+	}
+	a.record(AuditUpdate, "", "days", AuditDoc{Days: before}, AuditDoc{Days: days})
+	return nil
+}
+
+func (a *Audited) SetTypes(types map[string]int) error {
+	before, err := a.Store.GetTypes()
+	if err != nil {
+		return err
+	}
+	if err := a.Store.SetTypes(types); err != nil {
+		return err
+	}
+	a.record(AuditUpdate, "", "types", AuditDoc{Types: before}, AuditDoc{Types: types})
+	return nil
+}
+
+// the stored grant with the ID, nil when there is none
+func (a *Audited) grant(id string) (*Grant, error) {
+	grants, err := a.Store.GetGrants(bson.M{"_id": id})
+	if err != nil || len(grants) == 0 {
+		return nil, err
+	}
+	return &grants[0], nil
+}
+
+func (a *Audited) SaveGrant(g Grant) error {
+	g.ID = GrantID(g.UserID, g.Group)
+	before, err := a.grant(g.ID)
+	if err != nil {
+		return err
+	}
+	if err := a.Store.SaveGrant(g); err != nil {
+		return err
+	}
+	if before == nil {
+		a.record(AuditInsert, g.Group, g.ID, AuditDoc{}, AuditDoc{Grant: &g})
+	} else {
+		a.record(AuditUpdate, g.Group, g.ID, AuditDoc{Grant: before}, AuditDoc{Grant: &g})
+	}
+	return nil
+}
+
+func (a *Audited) DeleteGrant(userID int64, group string) error {
+	before, err := a.grant(GrantID(userID, group))
+	if err != nil {
+		return err
+	}
+	if err := a.Store.DeleteGrant(userID, group); err != nil {
+		return err
+	}
+	if before != nil {
+		a.record(AuditDelete, group, before.ID, AuditDoc{Grant: before}, AuditDoc{})
+	}
+	return nil
+}
+
+// reverts the change of the entry in the name of the user and marks the
+// entry undone. the revert is recorded as well
+func Undo(db Store, e AuditEntry, userID int64) error {
+	if e.Undone {
+		return fmt.Errorf("error: change %v is already undone", e.ID.Hex())
+	}
+	a := Audit(db, userID)
+	a.reverts = e.ID
+	var err error
+	switch {
+	case e.After.Lecture != nil && e.Action == AuditInsert:
+		err = a.DeleteLecture(e.Key)
+	case e.Before.Lecture != nil && e.Action == AuditUpdate:
+		err = a.UpdateLecture(e.Before.Lecture.ID, *e.Before.Lecture)
+	case e.Before.Lecture != nil && e.Action == AuditDelete:
+		err = a.InsertLecture(*e.Before.Lecture)
+	case e.After.Subject != nil && e.Action == AuditInsert:
+		err = a.DeleteSubject(e.Group, e.Key)
+	case e.Before.Subject != nil && e.Action == AuditUpdate:
+		err = a.UpdateSubject(*e.Before.Subject)
+	case e.Before.Subject != nil && e.Action == AuditDelete:
+		err = a.InsertSubject(*e.Before.Subject)
+	case e.After.Override != nil && e.Action == AuditInsert:
+		err = a.DeleteOverride(e.Key)
+	case e.Before.Override != nil && e.Action == AuditDelete:
+		err = a.InsertOverride(*e.Before.Override)
+	case e.Before.Group != nil && e.Action == AuditUpdate:
+		err = a.UpdateGroup(*e.Before.Group)
+	case e.After.Grant != nil && e.Action == AuditInsert:
+		err = RevokeGrant(a, e.After.Grant.UserID, e.After.Grant.Group)
+	case e.Before.Grant != nil && e.Action != AuditInsert:
+		err = a.SaveGrant(*e.Before.Grant)
+	case e.Before.Periods != nil:
+		err = a.SetPeriods(e.Before.Periods)
+	case e.Before.Days != nil:
+		err = a.SetDays(e.Before.Days)
+	case e.Before.Types != nil:
+		err = a.SetTypes(e.Before.Types)
+	default:
+		err = fmt.Errorf("error: change %v can't be undone", e.ID.Hex())
+	}
+	if err != nil {
+		return err
+	}
+	return db.SetAuditUndone(e.ID)
+}
@@ -0,0 +1,138 @@
+package mdb
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUndo(t *testing.T) {
+	lecture := Lecture{ID: primitive.NewObjectID(), Week: "0", Day: 1, Time: 1, Subject: "ОМО", Room: "101", SubGroup: "0", Group: "a"}
+	changed := lecture
+	changed.Room = "202"
+	tests := []struct {
+		name string
+		// prepares the store, the last change is undone
+		change func(db *Audited) error
+		want   []Lecture
+	}{
+		{"insert", func(db *Audited) error {
+			return db.InsertLecture(lecture)
+		}, nil},
+		{"update", func(db *Audited) error {
+			if err := db.Store.InsertLecture(lecture); err != nil {
+				return err
+			}
+			return db.UpdateLecture(lecture.ID, changed)
+		}, []Lecture{lecture}},
+		{"delete", func(db *Audited) error {
+			if err := db.Store.InsertLecture(lecture); err != nil {
+				return err
+			}
+			return db.DeleteLecture(lecture.ID.Hex())
+		}, []Lecture{lecture}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemDb()
+			db := Audit(mem, 1)
+			if err := tt.change(db); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := mem.GetAudit(bson.M{}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].UserID != 1 || entries[0].Key != lecture.ID.Hex() {
+				t.Fatalf("got audit entries %+v, want one of user 1", entries)
+			}
+			e := entries[0]
+			if err := Undo(mem, e, 2); err != nil {
+				t.Fatal(err)
+			}
+			got := mustLectures(t, mem)
+			if len(got) != len(tt.want) || (len(got) == 1 && got[0] != tt.want[0]) {
+				t.Errorf("got lectures %+v, want %+v", got, tt.want)
+			}
+
+			entries, err = mem.GetAudit(bson.M{"reverts": e.ID}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].UserID != 2 {
+				t.Errorf("got reverting entries %+v, want one of user 2", entries)
+			}
+			entries, err = mem.GetAudit(bson.M{"_id": e.ID}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !entries[0].Undone {
+				t.Error("the entry is not marked undone")
+			}
+			if err := Undo(mem, entries[0], 2); err == nil {
+				t.Error("undid a change twice")
+			}
+		})
+	}
+}
+
+func TestUndoGrant(t *testing.T) {
+	owner := Grant{UserID: 1, Role: RoleOwner}
+	admin := Grant{UserID: 2, Group: "a", Role: RoleAdmin}
+	tests := []struct {
+		name string
+		// the grants stored before the change
+		stored []Grant
+		// the change that is undone
+		change func(db *Audited) error
+		want   []int64
+		err    error
+	}{
+		{"grant", []Grant{owner}, func(db *Audited) error {
+			return db.SaveGrant(admin)
+		}, []int64{1}, nil},
+		{"second owner", []Grant{owner}, func(db *Audited) error {
+			return db.SaveGrant(Grant{UserID: 3, Role: RoleOwner})
+		}, []int64{1}, nil},
+		{"last owner", nil, func(db *Audited) error {
+			return db.SaveGrant(owner)
+		}, []int64{1}, ErrLastOwner},
+		{"revoke", []Grant{owner, admin}, func(db *Audited) error {
+			return RevokeGrant(db, admin.UserID, admin.Group)
+		}, []int64{1, 2}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemDb()
+			for _, g := range tt.stored {
+				if err := mem.SaveGrant(g); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tt.change(Audit(mem, 1)); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := mem.GetAudit(bson.M{}, 1)
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("got audit entries %v, %v", entries, err)
+			}
+			if err := Undo(mem, entries[0], 1); !errors.Is(err, tt.err) {
+				t.Fatalf("Undo: %v, want %v", err, tt.err)
+			}
+			grants, err := mem.GetGrants(bson.M{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var users []int64
+			for _, g := range grants {
+				users = append(users, g.UserID)
+			}
+			if !slices.Equal(users, tt.want) {
+				t.Errorf("grants of users %v after the undo, want %v", users, tt.want)
+			}
+		})
+	}
+}
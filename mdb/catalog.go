@@ -0,0 +1,448 @@
+package mdb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Catalog holds the reference data a group timetable is built from
+type Catalog struct {
+	Subjects map[string]Subject
+	Periods  map[int]Period
+	Days     map[int]string
+	Types    map[string]int
+}
+
+// loads the catalog of the group from the store
+func GetCatalog(db CatalogStore, group string) (Catalog, error) {
+	var cat Catalog
+	var err error
+	if cat.Subjects, err = db.GetSubjects(group); err != nil {
+		return Catalog{}, err
+	}
+	if cat.Periods, err = db.GetPeriods(); err != nil {
+		return Catalog{}, err
+	}
+	if cat.Days, err = db.GetDays(); err != nil {
+		return Catalog{}, err
+	}
+	if cat.Types, err = db.GetTypes(); err != nil {
+		return Catalog{}, err
+	}
+	return cat, nil
+}
+
+// fills empty parts of the catalog with the defaults compiled into the bot.
+// subjects are only seeded for the given group
+func SeedCatalog(db CatalogStore, group string) error {
+	periods, err := db.GetPeriods()
+	if err != nil {
+		return err
+	}
+	if len(periods) == 0 {
+		if err := db.SetPeriods(DefaultPeriods); err != nil {
+			return err
+		}
+		log.Printf("seeded periods")
+	}
+	days, err := db.GetDays()
+	if err != nil {
+		return err
+	}
+	if len(days) == 0 {
+		if err := db.SetDays(DefaultDays); err != nil {
+			return err
+		}
+		log.Printf("seeded days")
+	}
+	types, err := db.GetTypes()
+	if err != nil {
+		return err
+	}
+	if len(types) == 0 {
+		if err := db.SetTypes(DefaultTypes); err != nil {
+			return err
+		}
+		log.Printf("seeded types")
+	}
+	subjects, err := db.GetSubjects(group)
+	if err != nil {
+		return err
+	}
+	if len(subjects) == 0 {
+		for _, subject := range DefaultSubjects {
+			subject.Group = group
+			if err := db.InsertSubject(subject); err != nil {
+				return err
+			}
+		}
+		log.Printf("seeded subjects of group %s", group)
+	}
+	return nil
+}
+
+// parses a period written as "8:00-9:40"
+func ParsePeriod(number int, str string) (Period, error) {
+	start, end, ok := strings.Cut(strings.TrimSpace(str), "-")
+	if !ok {
+		return Period{}, fmt.Errorf("error: period %q must look like 8:00-9:40", str)
+	}
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	startMin, err := ParseClock(start)
+	if err != nil {
+		return Period{}, err
+	}
+	endMin, err := ParseClock(end)
+	if err != nil {
+		return Period{}, err
+	}
+	if endMin <= startMin {
+		return Period{}, fmt.Errorf("error: period %q ends before it starts", str)
+	}
+	return Period{Number: number, Start: start, End: end}, nil
+}
+
+// returns the start and the end of the period in minutes since midnight
+func (p Period) Minutes() (start int, end int, err error) {
+	if start, err = ParseClock(p.Start); err != nil {
+		return 0, 0, err
+	}
+	if end, err = ParseClock(p.End); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// converts "HH:MM" to minutes since midnight
+func ParseClock(clock string) (int, error) {
+	h, m, ok := strings.Cut(clock, ":")
+	hours, err1 := strconv.Atoi(h)
+	mins, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hours < 0 || hours > 23 || mins < 0 || mins > 59 {
+		return 0, fmt.Errorf("error: invalid time %q", clock)
+	}
+	return hours*60 + mins, nil
+}
+
+// PeriodsInUseError lists the stored lectures that keep periods from being
+// removed
+type PeriodsInUseError struct {
+	Lectures []Lecture
+}
+
+func (e *PeriodsInUseError) Error() string {
+	ids := make([]string, len(e.Lectures))
+	for i, l := range e.Lectures {
+		ids[i] = l.ID.Hex()
+	}
+	return fmt.Sprintf("lectures %v use the removed periods", strings.Join(ids, ", "))
+}
+
+// SetPeriodsChecked replaces the periods unless stored lectures of any group
+// are in a period it removes, those are listed by a *PeriodsInUseError
+func SetPeriodsChecked(db Store, periods map[int]Period) error {
+	current, err := db.GetPeriods()
+	if err != nil {
+		return err
+	}
+	var removed []int
+	for number := range current {
+		if _, ok := periods[number]; !ok {
+			removed = append(removed, number)
+		}
+	}
+	if len(removed) > 0 {
+		lectures, err := db.GetLectures(bson.M{"time": bson.M{"$in": removed}})
+		if err != nil {
+			return err
+		}
+		if len(lectures) > 0 {
+			return &PeriodsInUseError{Lectures: lectures}
+		}
+	}
+	return db.SetPeriods(periods)
+}
+
+func validateSubject(subject Subject) error {
+	if subject.Key == "" || subject.Name == "" {
+		return fmt.Errorf("error: subject needs a key and a name")
+	}
+	if subject.Group == "" {
+		return fmt.Errorf("error: subject needs a group")
+	}
+	return nil
+}
+
+func (d *Db) InsertSubject(subject Subject) error {
+	if err := validateSubject(subject); err != nil {
+		return err
+	}
+	filter := bson.M{"group": subject.Group, "key": subject.Key}
+	count, err := d.SubjectCollection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return fmt.Errorf("error inserting subject: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("subject %s already exists", subject.Key)
+	}
+	if _, err := d.SubjectCollection.InsertOne(context.TODO(), subject); err != nil {
+		return fmt.Errorf("error inserting subject: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) UpdateSubject(subject Subject) error {
+	if err := validateSubject(subject); err != nil {
+		return err
+	}
+	filter := bson.M{"group": subject.Group, "key": subject.Key}
+	result, err := d.SubjectCollection.ReplaceOne(context.TODO(), filter, subject)
+	if err != nil {
+		return fmt.Errorf("error updating subject: %w", err)
+	}
+	if result.MatchedCount < 1 {
+		return fmt.Errorf("subject %s: %w", subject.Key, ErrNotFound)
+	}
+	return nil
+}
+
+func (d *Db) DeleteSubject(group, key string) error {
+	result, err := d.SubjectCollection.DeleteOne(context.TODO(), bson.M{"group": group, "key": key})
+	if err != nil {
+		return fmt.Errorf("error deleting subject: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("subject %s: %w", key, ErrNotFound)
+	}
+	return nil
+}
+
+func (d *Db) GetSubjects(group string) (map[string]Subject, error) {
+	var subjects []Subject
+	if err := findAll(d.SubjectCollection, bson.M{"group": group}, &subjects); err != nil {
+		return nil, fmt.Errorf("error getting subjects: %w", err)
+	}
+	out := make(map[string]Subject, len(subjects))
+	for _, subject := range subjects {
+		out[subject.Key] = subject
+	}
+	return out, nil
+}
+
+func (d *Db) SetPeriods(periods map[int]Period) error {
+	var docs []any
+	for number, period := range periods {
+		period.Number = number
+		docs = append(docs, period)
+	}
+	if err := replaceAll(d.PeriodCollection, docs); err != nil {
+		return fmt.Errorf("error setting periods: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetPeriods() (map[int]Period, error) {
+	var periods []Period
+	if err := findAll(d.PeriodCollection, bson.M{}, &periods); err != nil {
+		return nil, fmt.Errorf("error getting periods: %w", err)
+	}
+	out := make(map[int]Period, len(periods))
+	for _, period := range periods {
+		out[period.Number] = period
+	}
+	return out, nil
+}
+
+func (d *Db) SetDays(days map[int]string) error {
+	var docs []any
+	for number, name := range days {
+		docs = append(docs, Day{Number: number, Name: name})
+	}
+	if err := replaceAll(d.DayCollection, docs); err != nil {
+		return fmt.Errorf("error setting days: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetDays() (map[int]string, error) {
+	var days []Day
+	if err := findAll(d.DayCollection, bson.M{}, &days); err != nil {
+		return nil, fmt.Errorf("error getting days: %w", err)
+	}
+	out := make(map[int]string, len(days))
+	for _, day := range days {
+		out[day.Number] = day.Name
+	}
+	return out, nil
+}
+
+func (d *Db) SetTypes(types map[string]int) error {
+	var docs []any
+	for key, order := range types {
+		docs = append(docs, LectureType{Key: key, Order: order})
+	}
+	if err := replaceAll(d.TypeCollection, docs); err != nil {
+		return fmt.Errorf("error setting types: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetTypes() (map[string]int, error) {
+	var types []LectureType
+	if err := findAll(d.TypeCollection, bson.M{}, &types); err != nil {
+		return nil, fmt.Errorf("error getting types: %w", err)
+	}
+	out := make(map[string]int, len(types))
+	for _, t := range types {
+		out[t.Key] = t.Order
+	}
+	return out, nil
+}
+
+func findAll(collection *mongo.Collection, filter bson.M, results any) error {
+	cursor, err := collection.Find(context.TODO(), filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.TODO())
+	return cursor.All(context.TODO(), results)
+}
+
+// replaces the whole content of a small collection
+func replaceAll(collection *mongo.Collection, docs []any) error {
+	if _, err := collection.DeleteMany(context.TODO(), bson.M{}); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	_, err := collection.InsertMany(context.TODO(), docs)
+	return err
+}
+
+func subjectKey(group, key string) string {
+	return group + "/" + key
+}
+
+func (m *MemDb) InsertSubject(subject Subject) error {
+	if err := validateSubject(subject); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subjects[subjectKey(subject.Group, subject.Key)]; ok {
+		return fmt.Errorf("subject %s already exists", subject.Key)
+	}
+	if err := put(m, m.subjects, subjectKey(subject.Group, subject.Key), subject); err != nil {
+		return fmt.Errorf("error inserting subject: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) UpdateSubject(subject Subject) error {
+	if err := validateSubject(subject); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subjects[subjectKey(subject.Group, subject.Key)]; !ok {
+		return fmt.Errorf("subject %s: %w", subject.Key, ErrNotFound)
+	}
+	if err := put(m, m.subjects, subjectKey(subject.Group, subject.Key), subject); err != nil {
+		return fmt.Errorf("error updating subject: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) DeleteSubject(group, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subjects[subjectKey(group, key)]; !ok {
+		return fmt.Errorf("subject %s: %w", key, ErrNotFound)
+	}
+	if err := remove(m, m.subjects, subjectKey(group, key)); err != nil {
+		return fmt.Errorf("error deleting subject: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetSubjects(group string) (map[string]Subject, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]Subject)
+	for _, subject := range m.subjects {
+		if subject.Group == group {
+			out[subject.Key] = subject
+		}
+	}
+	return out, nil
+}
+
+// replace swaps the content of a small collection and saves, restoring it if
+// saving fails. must be called with the write lock held
+func replace[K comparable, V any](m *MemDb, coll *map[K]V, next map[K]V) error {
+	old := *coll
+	*coll = next
+	if err := m.save(); err != nil {
+		*coll = old
+		return err
+	}
+	return nil
+}
+
+func (m *MemDb) SetPeriods(periods map[int]Period) error {
+	next := make(map[int]Period, len(periods))
+	for number, period := range periods {
+		period.Number = number
+		next[number] = period
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := replace(m, &m.periods, next); err != nil {
+		return fmt.Errorf("error setting periods: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetPeriods() (map[int]Period, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.periods), nil
+}
+
+func (m *MemDb) SetDays(days map[int]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := replace(m, &m.days, maps.Clone(days)); err != nil {
+		return fmt.Errorf("error setting days: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetDays() (map[int]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.days), nil
+}
+
+func (m *MemDb) SetTypes(types map[string]int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := replace(m, &m.types, maps.Clone(types)); err != nil {
+		return fmt.Errorf("error setting types: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetTypes() (map[string]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.types), nil
+}
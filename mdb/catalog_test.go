@@ -0,0 +1,68 @@
+package mdb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetPeriodsChecked(t *testing.T) {
+	periods := func(numbers ...int) map[int]Period {
+		out := make(map[int]Period)
+		for _, n := range numbers {
+			out[n] = Period{Number: n, Start: "8:00", End: "9:40"}
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		periods map[int]Period
+		// how many lectures keep the periods from being set
+		inUse int
+	}{
+		{"same periods", periods(1, 2, 3), 0},
+		{"added period", periods(1, 2, 3, 4), 0},
+		{"unused period removed", periods(1, 3), 0},
+		{"used period removed", periods(1, 2), 1},
+		{"period of two groups removed", periods(2, 3), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := NewMemDb()
+			if err := db.SetPeriods(periods(1, 2, 3)); err != nil {
+				t.Fatal(err)
+			}
+			for _, l := range []Lecture{
+				{Week: "0", Day: 1, Time: 1, Subject: "ОМО", Room: "101", SubGroup: "0", Group: "a"},
+				{Week: "0", Day: 1, Time: 3, Subject: "ТЭ", Room: "102", SubGroup: "0", Group: "a"},
+			} {
+				if err := db.InsertLecture(l); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := db.InsertLecture(Lecture{Week: "0", Day: 2, Time: 1, Subject: "ФК", Room: "103", SubGroup: "0", Group: "b"}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := SetPeriodsChecked(db, tt.periods)
+			var inUse *PeriodsInUseError
+			if errors.As(err, &inUse) {
+				if len(inUse.Lectures) != tt.inUse {
+					t.Errorf("got lectures %v in use, want %d", inUse.Lectures, tt.inUse)
+				}
+			} else if err != nil || tt.inUse > 0 {
+				t.Fatalf("got error %v, want %d lectures in use", err, tt.inUse)
+			}
+			got, err := db.GetPeriods()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := len(tt.periods)
+			if tt.inUse > 0 {
+				want = 3
+			}
+			if len(got) != want {
+				t.Errorf("got %d periods, want %d", len(got), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package mdb
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Conflict is a stored lecture that clashes with a lecture about to be saved,
+// or another lecture of the same import
+type Conflict struct {
+	Lecture Lecture
+	// the clashing lecture is the one at Index of the same import, not a
+	// stored one
+	Pending bool
+	Index   int
+	// the students of the lecture already have a lecture in the period
+	Overlap bool
+	// the room is already taken in the period
+	Room bool
+}
+
+func (c Conflict) String() string {
+	var reasons []string
+	if c.Overlap {
+		reasons = append(reasons, "same period")
+	}
+	if c.Room {
+		reasons = append(reasons, fmt.Sprintf("room %v taken", c.Lecture.Room))
+	}
+	l := c.Lecture
+	id := l.ID.Hex()
+	if c.Pending {
+		id = fmt.Sprintf("import #%d", c.Index+1)
+	}
+	return fmt.Sprintf("[ %v ] %v, week %v, day %v, period %v, subgroup %v, group %v: %v",
+		id, l.Subject, l.Week, l.Day, l.Time, l.SubGroup, l.Group, strings.Join(reasons, ", "))
+}
+
+// ConflictError lists the clashes that keep a lecture from being saved
+// without an override
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		lines[i] = c.String()
+	}
+	return "lecture conflicts with stored lectures:\n" + strings.Join(lines, "\n")
+}
+
+// lectures of every week ("0") and of the whole group ("0") overlap any week
+// and any subgroup
+func overlaps(a, b string) bool {
+	return a == "0" || b == "0" || a == b
+}
+
+// rooms that do not stand for a place and can't be double-booked
+func bookable(room string) bool {
+	room = strings.TrimSpace(room)
+	return room != "" && room != "-"
+}
+
+// how the other lecture clashes with the lecture, false when it does not
+func clash(lecture, other Lecture) (Conflict, bool) {
+	if lecture.Day != other.Day || lecture.Time != other.Time || !overlaps(lecture.Week, other.Week) {
+		return Conflict{}, false
+	}
+	c := Conflict{
+		Lecture: other,
+		Overlap: other.Group == lecture.Group && overlaps(lecture.SubGroup, other.SubGroup),
+		Room:    bookable(lecture.Room) && strings.EqualFold(strings.TrimSpace(lecture.Room), strings.TrimSpace(other.Room)),
+	}
+	return c, c.Overlap || c.Room
+}
+
+// finds the stored lectures clashing with the lecture: the ones its group
+// and subgroup already have in its week, day and period, and the ones of any
+// group in its room at that time. the stored version of the lecture itself
+// is not a clash
+func FindConflicts(db LectureStore, lecture Lecture) ([]Conflict, error) {
+	others, err := db.GetLectures(bson.M{"day": lecture.Day, "time": lecture.Time})
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []Conflict
+	for _, other := range others {
+		if !lecture.ID.IsZero() && other.ID == lecture.ID {
+			continue
+		}
+		if c, ok := clash(lecture, other); ok {
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts, nil
+}
+
+// checks the lecture against the stored ones, a *ConflictError lists the
+// clashes
+func ValidateLecture(db LectureStore, lecture Lecture) error {
+	if err := validateWeek(lecture.Week); err != nil {
+		return err
+	}
+	conflicts, err := FindConflicts(db, lecture)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// finds the clashes of each lecture of an import, by position: with stored
+// lectures the import does not replace, and with the lectures before it in
+// the import
+func ImportConflicts(db LectureStore, lectures []Lecture) ([][]Conflict, error) {
+	replaced := make(map[primitive.ObjectID]bool)
+	for _, lecture := range lectures {
+		if !lecture.ID.IsZero() {
+			replaced[lecture.ID] = true
+		}
+	}
+	conflicts := make([][]Conflict, len(lectures))
+	for i, lecture := range lectures {
+		stored, err := FindConflicts(db, lecture)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range stored {
+			if !replaced[c.Lecture.ID] {
+				conflicts[i] = append(conflicts[i], c)
+			}
+		}
+		for j, other := range lectures[:i] {
+			if c, ok := clash(lecture, other); ok {
+				c.Pending, c.Index = true, j
+				conflicts[i] = append(conflicts[i], c)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// the checked paths every lecture change goes through. they refuse a
+// lecture clashing with others with a *ConflictError, unless force is set
+
+// InsertLectureChecked inserts the lecture if it does not clash
+func InsertLectureChecked(db LectureStore, lecture Lecture, force bool) error {
+	if !force {
+		if err := ValidateLecture(db, lecture); err != nil {
+			return err
+		}
+	}
+	return db.InsertLecture(lecture)
+}
+
+// UpdateLectureChecked replaces the lecture with the ID if the new version
+// does not clash
+func UpdateLectureChecked(db LectureStore, ID primitive.ObjectID, lecture Lecture, force bool) error {
+	if !force {
+		check := lecture
+		check.ID = ID
+		if err := ValidateLecture(db, check); err != nil {
+			return err
+		}
+	}
+	return db.UpdateLecture(ID, lecture)
+}
+
+// ImportLecturesChecked imports the lectures if none of them clashes
+func ImportLecturesChecked(db LectureStore, lectures []Lecture, force bool) error {
+	if !force {
+		conflicts, err := ImportConflicts(db, lectures)
+		if err != nil {
+			return err
+		}
+		var all []Conflict
+		for _, c := range conflicts {
+			all = append(all, c...)
+		}
+		if len(all) > 0 {
+			return &ConflictError{Conflicts: all}
+		}
+	}
+	return db.ImportLectures(lectures)
+}
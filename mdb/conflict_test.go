@@ -0,0 +1,102 @@
+package mdb
+
+import "testing"
+
+func TestFindConflicts(t *testing.T) {
+	db := NewMemDb()
+	stored := Lecture{Week: "1", Day: 2, Time: 3, Subject: "ОМО", Room: "101", SubGroup: "1", Group: "a"}
+	if err := db.InsertLecture(stored); err != nil {
+		t.Fatal(err)
+	}
+	stored = mustLectures(t, db)[0]
+
+	at := func(change func(l *Lecture)) Lecture {
+		l := Lecture{Week: "1", Day: 2, Time: 3, Subject: "ТЭ", Room: "202", SubGroup: "1", Group: "a"}
+		change(&l)
+		return l
+	}
+	tests := []struct {
+		name          string
+		lecture       Lecture
+		overlap, room bool
+	}{
+		{"same subgroup", at(func(l *Lecture) {}), true, false},
+		{"whole group", at(func(l *Lecture) { l.SubGroup = "0" }), true, false},
+		{"every week", at(func(l *Lecture) { l.Week = "0" }), true, false},
+		{"same room", at(func(l *Lecture) { l.Room = " 101 " }), true, true},
+		{"room of another group", at(func(l *Lecture) { l.Group, l.Room = "b", "101" }), false, true},
+		{"other subgroup", at(func(l *Lecture) { l.SubGroup = "2" }), false, false},
+		{"other week", at(func(l *Lecture) { l.Week = "2" }), false, false},
+		{"other period", at(func(l *Lecture) { l.Time = 4 }), false, false},
+		{"other day", at(func(l *Lecture) { l.Day = 3 }), false, false},
+		{"another group", at(func(l *Lecture) { l.Group = "b" }), false, false},
+		{"no room", at(func(l *Lecture) { l.Group, l.Room = "b", "-" }), false, false},
+		{"itself", stored, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts, err := FindConflicts(db, tt.lecture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.overlap && !tt.room {
+				if len(conflicts) != 0 {
+					t.Errorf("got conflicts %v, want none", conflicts)
+				}
+				return
+			}
+			if len(conflicts) != 1 {
+				t.Fatalf("got %d conflicts, want 1", len(conflicts))
+			}
+			c := conflicts[0]
+			if c.Lecture.ID != stored.ID || c.Overlap != tt.overlap || c.Room != tt.room {
+				t.Errorf("got %+v, want overlap %v, room %v", c, tt.overlap, tt.room)
+			}
+		})
+	}
+}
+
+func TestImportConflicts(t *testing.T) {
+	db := NewMemDb()
+	if err := db.InsertLecture(Lecture{Week: "0", Day: 1, Time: 1, Subject: "ОМО", Room: "101", SubGroup: "0", Group: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	stored := mustLectures(t, db)[0]
+	replaced := stored
+	replaced.Room = "102"
+	lectures := []Lecture{
+		replaced,
+		{Week: "1", Day: 1, Time: 1, Subject: "ТЭ", Room: "103", SubGroup: "1", Group: "a"},
+		{Week: "2", Day: 4, Time: 2, Subject: "ФК", Room: "104", SubGroup: "0", Group: "a"},
+	}
+	conflicts, err := ImportConflicts(db, lectures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts[0]) != 0 || len(conflicts[2]) != 0 {
+		t.Errorf("got conflicts %v, want them for the second lecture only", conflicts)
+	}
+	// the stored lecture is replaced by the first one, which clashes instead
+	if len(conflicts[1]) != 1 || !conflicts[1][0].Pending || conflicts[1][0].Index != 0 {
+		t.Errorf("got conflicts %v for the second lecture, want the first lecture of the import", conflicts[1])
+	}
+
+	if err := ImportLecturesChecked(db, lectures, false); err == nil {
+		t.Error("imported clashing lectures")
+	}
+	if err := ImportLecturesChecked(db, lectures, true); err != nil {
+		t.Errorf("forced import: %v", err)
+	}
+	if n := len(mustLectures(t, db)); n != 3 {
+		t.Errorf("%d lectures after the import, want 3", n)
+	}
+}
+
+func mustLectures(t *testing.T, db *MemDb) []Lecture {
+	t.Helper()
+	lectures, err := db.GetLectures(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lectures
+}
@@ -0,0 +1,149 @@
+package mdb
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// snapshot is the on-disk layout of the file backend
+type snapshot struct {
+	Lectures      []Lecture      `json:"lectures"`
+	Groups        []Group        `json:"groups"`
+	Chats         []Chat         `json:"chats"`
+	Subjects      []Subject      `json:"subjects"`
+	Periods       []Period       `json:"periods"`
+	Days          []Day          `json:"days"`
+	Types         []LectureType  `json:"types"`
+	Overrides     []Override     `json:"overrides"`
+	Tokens        []Token        `json:"tokens"`
+	Subscriptions []Subscription `json:"subscriptions"`
+	Sessions      []Session      `json:"sessions"`
+	Prefs         []Prefs        `json:"prefs"`
+	Audit         []AuditEntry   `json:"audit"`
+	Roles         []Grant        `json:"roles"`
+}
+
+// NewFileDb opens the single-file backend stored at path, creating it on
+// the first write. every change rewrites the whole file, which is fine for
+// the size of a group timetable
+func NewFileDb(path string) (*MemDb, error) {
+	m := NewMemDb()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if len(data) > 0 {
+		var snap snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", path, err)
+		}
+		for _, lecture := range snap.Lectures {
+			m.lectures[lecture.ID] = lecture
+		}
+		for _, group := range snap.Groups {
+			m.groups[group.Key] = group
+		}
+		for _, chat := range snap.Chats {
+			m.chats[chat.ID] = chat
+		}
+		for _, subject := range snap.Subjects {
+			m.subjects[subjectKey(subject.Group, subject.Key)] = subject
+		}
+		for _, period := range snap.Periods {
+			m.periods[period.Number] = period
+		}
+		for _, day := range snap.Days {
+			m.days[day.Number] = day.Name
+		}
+		for _, t := range snap.Types {
+			m.types[t.Key] = t.Order
+		}
+		for _, o := range snap.Overrides {
+			m.overrides[o.ID] = o
+		}
+		for _, t := range snap.Tokens {
+			m.tokens[t.Token] = t
+		}
+		for _, s := range snap.Subscriptions {
+			m.subscriptions[s.ID] = s
+		}
+		for _, s := range snap.Sessions {
+			m.sessions[s.ID] = s
+		}
+		for _, p := range snap.Prefs {
+			m.prefs[p.UserID] = p
+		}
+		for _, e := range snap.Audit {
+			m.audit[e.ID] = e
+		}
+		for _, g := range snap.Roles {
+			m.roles[g.ID] = g
+		}
+	}
+	m.persist = func() error {
+		return m.writeFile(path)
+	}
+	return m, nil
+}
+
+// values returns the values of coll ordered by compare, so the file does
+// not change when the data did not
+func values[K comparable, V any](coll map[K]V, compare func(a, b V) int) []V {
+	out := make([]V, 0, len(coll))
+	for _, v := range coll {
+		out = append(out, v)
+	}
+	slices.SortFunc(out, compare)
+	return out
+}
+
+// writeFile must be called with the lock held
+func (m *MemDb) writeFile(path string) error {
+	snap := snapshot{
+		Lectures: values(m.lectures, func(a, b Lecture) int { return bytes.Compare(a.ID[:], b.ID[:]) }),
+		Groups:   values(m.groups, func(a, b Group) int { return cmp.Compare(a.Key, b.Key) }),
+		Chats:    values(m.chats, func(a, b Chat) int { return cmp.Compare(a.ID, b.ID) }),
+		Subjects: values(m.subjects, func(a, b Subject) int {
+			return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Key, b.Key))
+		}),
+		Periods:       values(m.periods, func(a, b Period) int { return cmp.Compare(a.Number, b.Number) }),
+		Overrides:     values(m.overrides, func(a, b Override) int { return bytes.Compare(a.ID[:], b.ID[:]) }),
+		Tokens:        values(m.tokens, func(a, b Token) int { return cmp.Compare(a.Token, b.Token) }),
+		Subscriptions: values(m.subscriptions, func(a, b Subscription) int { return cmp.Compare(a.ID, b.ID) }),
+		Sessions:      values(m.sessions, func(a, b Session) int { return cmp.Compare(a.ID, b.ID) }),
+		Prefs:         values(m.prefs, func(a, b Prefs) int { return cmp.Compare(a.UserID, b.UserID) }),
+		Audit:         values(m.audit, func(a, b AuditEntry) int { return bytes.Compare(a.ID[:], b.ID[:]) }),
+		Roles:         values(m.roles, func(a, b Grant) int { return cmp.Compare(a.ID, b.ID) }),
+	}
+	for number, name := range m.days {
+		snap.Days = append(snap.Days, Day{Number: number, Name: name})
+	}
+	slices.SortFunc(snap.Days, func(a, b Day) int { return cmp.Compare(a.Number, b.Number) })
+	for key, order := range m.types {
+		snap.Types = append(snap.Types, LectureType{Key: key, Order: order})
+	}
+	slices.SortFunc(snap.Types, func(a, b LectureType) int { return cmp.Compare(a.Key, b.Key) })
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,216 @@
+package mdb
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func validateGroup(group Group) error {
+	if group.Key == "" || strings.ContainsAny(group.Key, " \t\n") {
+		return fmt.Errorf("error: group key must be a single word")
+	}
+	return nil
+}
+
+func (d *Db) AssignLectureGroup(group string) (int, error) {
+	filter := bson.M{"$or": []bson.M{
+		{"group": bson.M{"$exists": false}},
+		{"group": ""},
+	}}
+	result, err := d.LectureCollection.UpdateMany(context.TODO(), filter, bson.M{"$set": bson.M{"group": group}})
+	if err != nil {
+		return 0, fmt.Errorf("error assigning lecture group: %w", err)
+	}
+	return int(result.ModifiedCount), nil
+}
+
+func (d *Db) InsertGroup(group Group) error {
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	_, err := d.GroupCollection.InsertOne(context.TODO(), group)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("group %s already exists", group.Key)
+	}
+	if err != nil {
+		return fmt.Errorf("error inserting group: %w", err)
+	}
+	log.Printf("Inserted group: %v\n", group.Key)
+	return nil
+}
+
+func (d *Db) UpdateGroup(group Group) error {
+	result, err := d.GroupCollection.ReplaceOne(context.TODO(), bson.M{"_id": group.Key}, group)
+	if err != nil {
+		return fmt.Errorf("error updating group: %w", err)
+	}
+	if result.MatchedCount < 1 {
+		return fmt.Errorf("group %s: %w", group.Key, ErrNotFound)
+	}
+	return nil
+}
+
+func (d *Db) GetGroup(key string) (Group, error) {
+	var group Group
+	err := d.GroupCollection.FindOne(context.TODO(), bson.M{"_id": key}).Decode(&group)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Group{}, fmt.Errorf("group %s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return Group{}, fmt.Errorf("error getting group: %w", err)
+	}
+	return group, nil
+}
+
+func (d *Db) GetGroups() ([]Group, error) {
+	cursor, err := d.GroupCollection.Find(context.TODO(), bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("error getting groups: %w", err)
+	}
+	defer cursor.Close(context.TODO())
+	var groups []Group
+	if err = cursor.All(context.TODO(), &groups); err != nil {
+		return nil, fmt.Errorf("error decoding group: %w", err)
+	}
+	return groups, nil
+}
+
+func (d *Db) SaveChat(chat Chat) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := d.ChatCollection.ReplaceOne(context.TODO(), bson.M{"_id": chat.ID}, chat, opts)
+	if err != nil {
+		return fmt.Errorf("error saving chat: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetChat(chatID int64) (Chat, error) {
+	var chat Chat
+	err := d.ChatCollection.FindOne(context.TODO(), bson.M{"_id": chatID}).Decode(&chat)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Chat{}, fmt.Errorf("chat %d: %w", chatID, ErrNotFound)
+	}
+	if err != nil {
+		return Chat{}, fmt.Errorf("error getting chat: %w", err)
+	}
+	return chat, nil
+}
+
+func (d *Db) GetChats(filter bson.M) ([]Chat, error) {
+	var chats []Chat
+	if err := findAll(d.ChatCollection, filter, &chats); err != nil {
+		return nil, fmt.Errorf("error getting chats: %w", err)
+	}
+	return chats, nil
+}
+
+func (m *MemDb) AssignLectureGroup(group string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for id, lecture := range m.lectures {
+		if lecture.Group == "" {
+			lecture.Group = group
+			m.lectures[id] = lecture
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := m.save(); err != nil {
+		return 0, fmt.Errorf("error assigning lecture group: %w", err)
+	}
+	return count, nil
+}
+
+func (m *MemDb) InsertGroup(group Group) error {
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[group.Key]; ok {
+		return fmt.Errorf("group %s already exists", group.Key)
+	}
+	if err := put(m, m.groups, group.Key, group); err != nil {
+		return fmt.Errorf("error inserting group: %w", err)
+	}
+	log.Printf("Inserted group: %v\n", group.Key)
+	return nil
+}
+
+func (m *MemDb) UpdateGroup(group Group) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.groups[group.Key]; !ok {
+		return fmt.Errorf("group %s: %w", group.Key, ErrNotFound)
+	}
+	if err := put(m, m.groups, group.Key, group); err != nil {
+		return fmt.Errorf("error updating group: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetGroup(key string) (Group, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	group, ok := m.groups[key]
+	if !ok {
+		return Group{}, fmt.Errorf("group %s: %w", key, ErrNotFound)
+	}
+	return group, nil
+}
+
+func (m *MemDb) GetGroups() ([]Group, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return values(m.groups, func(a, b Group) int {
+		return strings.Compare(a.Key, b.Key)
+	}), nil
+}
+
+func (m *MemDb) SaveChat(chat Chat) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := put(m, m.chats, chat.ID, chat); err != nil {
+		return fmt.Errorf("error saving chat: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetChat(chatID int64) (Chat, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	chat, ok := m.chats[chatID]
+	if !ok {
+		return Chat{}, fmt.Errorf("chat %d: %w", chatID, ErrNotFound)
+	}
+	return chat, nil
+}
+
+func (m *MemDb) GetChats(filter bson.M) ([]Chat, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var chats []Chat
+	for _, chat := range m.chats {
+		doc, err := toDoc(chat)
+		if err != nil {
+			return nil, fmt.Errorf("error getting chats: %w", err)
+		}
+		if matches(doc, filter) {
+			chats = append(chats, chat)
+		}
+	}
+	slices.SortFunc(chats, func(a, b Chat) int { return cmp.Compare(a.ID, b.ID) })
+	return chats, nil
+}
@@ -2,6 +2,7 @@ package mdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"slices"
@@ -9,13 +10,14 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // inserts new lecture to the database
 func (d *Db) InsertLecture(lecture Lecture) error {
-	week, _ := strconv.Atoi(lecture.Week)
-	if week < 0 || week > 4 {
-		return fmt.Errorf("error: week must be between 0 - 4")
+	if err := validateWeek(lecture.Week); err != nil {
+		return err
 	}
 	result, err := d.LectureCollection.InsertOne(context.TODO(), lecture)
 	if err != nil {
@@ -64,6 +66,21 @@ func (d *Db) GetLectures(filter bson.M) ([]Lecture, error) {
 		return nil, fmt.Errorf("error decoding lecture: %w", err)
 	}
 
+	sortLectures(lectures)
+
+	return lectures, nil
+}
+
+func validateWeek(w string) error {
+	week, _ := strconv.Atoi(w)
+	if week < 0 || week > CycleWeeks {
+		return fmt.Errorf("error: week must be between 0 - %d", CycleWeeks)
+	}
+	return nil
+}
+
+// orders lectures by period, then by week
+func sortLectures(lectures []Lecture) {
 	slices.SortStableFunc(lectures, func(a, b Lecture) int {
 		if a.Week < b.Week {
 			return -1
@@ -81,8 +98,6 @@ func (d *Db) GetLectures(filter bson.M) ([]Lecture, error) {
 		}
 		return 0
 	})
-
-	return lectures, nil
 }
 
 func (d *Db) DeleteLecture(lectureID string) error {
@@ -100,3 +115,121 @@ func (d *Db) DeleteLecture(lectureID string) error {
 	}
 	return nil
 }
+
+// ImportLectures stores all the lectures or none of them. lectures with the
+// ID of a stored lecture replace it, the others are inserted
+func (d *Db) ImportLectures(lectures []Lecture) error {
+	for i := range lectures {
+		if err := validateWeek(lectures[i].Week); err != nil {
+			return err
+		}
+		if lectures[i].ID.IsZero() {
+			lectures[i].ID = primitive.NewObjectID()
+		}
+	}
+	models := make([]mongo.WriteModel, len(lectures))
+	for i, lecture := range lectures {
+		models[i] = mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": lecture.ID}).SetReplacement(lecture).SetUpsert(true)
+	}
+	err := d.importInTransaction(models)
+	if isNoTransactions(err) {
+		err = d.importWithRollback(lectures, models)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("imported %v lectures\n", len(lectures))
+	return nil
+}
+
+func (d *Db) importInTransaction(models []mongo.WriteModel) error {
+	session, err := d.LectureCollection.Database().Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("error importing lectures: %w", err)
+	}
+	defer session.EndSession(context.TODO())
+	_, err = session.WithTransaction(context.TODO(), func(ctx mongo.SessionContext) (any, error) {
+		return d.LectureCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	})
+	if err != nil {
+		return fmt.Errorf("error importing lectures: %w", err)
+	}
+	return nil
+}
+
+// a standalone mongo server has no transactions
+func isNoTransactions(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && (serverErr.HasErrorCode(20) || serverErr.HasErrorMessage("Transaction numbers"))
+}
+
+// imports without a transaction and undoes a failed import by hand. when
+// that fails too the error says the import is partial
+func (d *Db) importWithRollback(lectures []Lecture, models []mongo.WriteModel) error {
+	ids := make([]primitive.ObjectID, len(lectures))
+	for i, lecture := range lectures {
+		ids[i] = lecture.ID
+	}
+	// keep what is about to be replaced
+	var previous []Lecture
+	if err := findAll(d.LectureCollection, bson.M{"_id": bson.M{"$in": ids}}, &previous); err != nil {
+		return fmt.Errorf("error importing lectures: %w", err)
+	}
+	_, err := d.LectureCollection.BulkWrite(context.TODO(), models, options.BulkWrite().SetOrdered(true))
+	if err == nil {
+		return nil
+	}
+	rbErr := func() error {
+		if _, err := d.LectureCollection.DeleteMany(context.TODO(), bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		if len(previous) == 0 {
+			return nil
+		}
+		docs := make([]any, len(previous))
+		for i, lecture := range previous {
+			docs[i] = lecture
+		}
+		_, err := d.LectureCollection.InsertMany(context.TODO(), docs)
+		return err
+	}()
+	if rbErr != nil {
+		log.Printf("error rolling back import: %v", rbErr)
+		return fmt.Errorf("error importing lectures: %w. undoing the import failed as well (%v), some of the lectures may be stored, check them with /lectures", err, rbErr)
+	}
+	return fmt.Errorf("error importing lectures, nothing was imported: %w", err)
+}
+
+// LectureQuery selects lectures of a group, zero fields match everything
+type LectureQuery struct {
+	Group string
+	// lectures of every week ("0") match any week
+	Week int
+	Day  int
+	// lectures of the whole group ("0") match any subgroup
+	SubGroup string
+	// the key of the subject
+	Subject string
+}
+
+// builds the mongo filter of the query
+func (q LectureQuery) Filter() bson.M {
+	conditions := []bson.M{{"group": q.Group}}
+	if q.Week != 0 {
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"week": fmt.Sprint(q.Week)},
+			{"week": "0"}}})
+	}
+	if q.Day != 0 {
+		conditions = append(conditions, bson.M{"day": q.Day})
+	}
+	if q.SubGroup != "" {
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"sub_group": q.SubGroup},
+			{"sub_group": "0"}}})
+	}
+	if q.Subject != "" {
+		conditions = append(conditions, bson.M{"subject": q.Subject})
+	}
+	return bson.M{"$and": conditions}
+}
@@ -0,0 +1,138 @@
+package mdb
+
+import (
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// toDoc converts a stored value into the document mongo would see, so the
+// in-memory backends can evaluate the same filters the mongo backend does
+func toDoc(v any) (bson.M, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// matches reports whether doc satisfies filter. only the part of the mongo
+// query language used by the bot is supported: $and, $or, $in, $ne,
+// $exists and plain equality
+func matches(doc bson.M, filter bson.M) bool {
+	for key, cond := range filter {
+		switch key {
+		case "$and":
+			for _, sub := range subFilters(cond) {
+				if !matches(doc, sub) {
+					return false
+				}
+			}
+		case "$or":
+			found := false
+			for _, sub := range subFilters(cond) {
+				if matches(doc, sub) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		default:
+			value, exists := doc[key]
+			if !matchValue(value, exists, cond) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchValue(value any, exists bool, cond any) bool {
+	ops, ok := cond.(bson.M)
+	if !ok {
+		return exists && equal(value, cond)
+	}
+	for op, arg := range ops {
+		switch op {
+		case "$in":
+			found := false
+			for _, item := range list(arg) {
+				if exists && equal(value, item) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		case "$ne":
+			if exists && equal(value, arg) {
+				return false
+			}
+		case "$exists":
+			if want, _ := arg.(bool); want != exists {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func subFilters(v any) []bson.M {
+	switch f := v.(type) {
+	case []bson.M:
+		return f
+	default:
+		var out []bson.M
+		for _, item := range list(v) {
+			if m, ok := item.(bson.M); ok {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+}
+
+func list(v any) []any {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// equal compares two values the way mongo does, treating all numeric types
+// as the same type
+func equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,45 @@
+package mdb
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMatches(t *testing.T) {
+	doc := bson.M{"group": "a", "day": int32(2), "week": "0", "sub_group": "1"}
+	tests := []struct {
+		name   string
+		filter bson.M
+		want   bool
+	}{
+		{"empty", bson.M{}, true},
+		{"equal", bson.M{"group": "a"}, true},
+		{"not equal", bson.M{"group": "b"}, false},
+		{"numbers of other types", bson.M{"day": 2}, true},
+		{"missing field", bson.M{"room": "101"}, false},
+		{"in", bson.M{"group": bson.M{"$in": []string{"b", "a"}}}, true},
+		{"not in", bson.M{"group": bson.M{"$in": []string{"b", "c"}}}, false},
+		{"ne", bson.M{"group": bson.M{"$ne": "b"}}, true},
+		{"ne equal", bson.M{"group": bson.M{"$ne": "a"}}, false},
+		{"ne missing", bson.M{"room": bson.M{"$ne": "101"}}, true},
+		{"exists", bson.M{"week": bson.M{"$exists": true}}, true},
+		{"not exists", bson.M{"room": bson.M{"$exists": false}}, true},
+		{"exists missing", bson.M{"room": bson.M{"$exists": true}}, false},
+		{"unknown operator", bson.M{"day": bson.M{"$gt": 1}}, false},
+		{"and", bson.M{"$and": []bson.M{{"group": "a"}, {"day": 2}}}, true},
+		{"and failing", bson.M{"$and": []bson.M{{"group": "a"}, {"day": 3}}}, false},
+		{"or", bson.M{"$or": []bson.M{{"week": "2"}, {"week": "0"}}}, true},
+		{"or failing", bson.M{"$or": []bson.M{{"week": "2"}, {"week": "3"}}}, false},
+		{"or of any", bson.M{"$or": []any{bson.M{"week": "0"}}}, true},
+		{"lecture query", LectureQuery{Group: "a", Week: 3, Day: 2, SubGroup: "1"}.Filter(), true},
+		{"lecture query of another subgroup", LectureQuery{Group: "a", SubGroup: "2"}.Filter(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(doc, tt.filter); got != tt.want {
+				t.Errorf("matches(%v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package mdb
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemDb keeps everything in memory. it is safe for concurrent use and, when
+// created with NewFileDb, writes itself to a single file after every change
+type MemDb struct {
+	mu            sync.RWMutex
+	lectures      map[primitive.ObjectID]Lecture
+	groups        map[string]Group
+	chats         map[int64]Chat
+	subjects      map[string]Subject
+	periods       map[int]Period
+	days          map[int]string
+	types         map[string]int
+	overrides     map[primitive.ObjectID]Override
+	tokens        map[string]Token
+	subscriptions map[string]Subscription
+	sessions      map[string]Session
+	prefs         map[int64]Prefs
+	audit         map[primitive.ObjectID]AuditEntry
+	roles         map[string]Grant
+	persist       func() error
+}
+
+func NewMemDb() *MemDb {
+	return &MemDb{
+		lectures:      make(map[primitive.ObjectID]Lecture),
+		groups:        make(map[string]Group),
+		chats:         make(map[int64]Chat),
+		subjects:      make(map[string]Subject),
+		periods:       make(map[int]Period),
+		days:          make(map[int]string),
+		types:         make(map[string]int),
+		overrides:     make(map[primitive.ObjectID]Override),
+		tokens:        make(map[string]Token),
+		subscriptions: make(map[string]Subscription),
+		sessions:      make(map[string]Session),
+		prefs:         make(map[int64]Prefs),
+		audit:         make(map[primitive.ObjectID]AuditEntry),
+		roles:         make(map[string]Grant),
+	}
+}
+
+// save must be called with the write lock held
+func (m *MemDb) save() error {
+	if m.persist == nil {
+		return nil
+	}
+	return m.persist()
+}
+
+// put stores value under key and saves, restoring the previous value if
+// saving fails. must be called with the write lock held
+func put[K comparable, V any](m *MemDb, coll map[K]V, key K, value V) error {
+	old, existed := coll[key]
+	coll[key] = value
+	if err := m.save(); err != nil {
+		if existed {
+			coll[key] = old
+		} else {
+			delete(coll, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// remove deletes key and saves, restoring it if saving fails. must be called
+// with the write lock held
+func remove[K comparable, V any](m *MemDb, coll map[K]V, key K) error {
+	old, existed := coll[key]
+	if !existed {
+		return nil
+	}
+	delete(coll, key)
+	if err := m.save(); err != nil {
+		coll[key] = old
+		return err
+	}
+	return nil
+}
+
+// inserts new lecture to the store
+func (m *MemDb) InsertLecture(lecture Lecture) error {
+	if err := validateWeek(lecture.Week); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lecture.ID.IsZero() {
+		lecture.ID = primitive.NewObjectID()
+	}
+	if _, ok := m.lectures[lecture.ID]; ok {
+		return fmt.Errorf("error inserting lecture: duplicate ID %s", lecture.ID.Hex())
+	}
+	if err := put(m, m.lectures, lecture.ID, lecture); err != nil {
+		return fmt.Errorf("error inserting lecture: %w", err)
+	}
+	log.Printf("Inserted lecture with ID: %v\n", lecture.ID)
+	return nil
+}
+
+func (m *MemDb) UpdateLecture(ID primitive.ObjectID, lecture Lecture) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.lectures[ID]; !ok {
+		return fmt.Errorf("lecture not found")
+	}
+	lecture.ID = ID
+	if err := put(m, m.lectures, ID, lecture); err != nil {
+		return fmt.Errorf("error updating lecture: %w", err)
+	}
+	log.Printf("updated lecture: %v\n", ID.Hex())
+	return nil
+}
+
+func (m *MemDb) GetLecture(lectureID string) (Lecture, error) {
+	ID, err := primitive.ObjectIDFromHex(lectureID)
+	if err != nil {
+		return Lecture{}, fmt.Errorf("error converting ObjectID from Hex: %w", err)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	lecture, ok := m.lectures[ID]
+	if !ok {
+		return Lecture{}, fmt.Errorf("error: lecture [ %s ] not found", lectureID)
+	}
+	return lecture, nil
+}
+
+func (m *MemDb) GetLectures(filter bson.M) ([]Lecture, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var lectures []Lecture
+	for _, lecture := range m.lectures {
+		doc, err := toDoc(lecture)
+		if err != nil {
+			return nil, fmt.Errorf("error getting lectures: %w", err)
+		}
+		if matches(doc, filter) {
+			lectures = append(lectures, lecture)
+		}
+	}
+	// object ids grow with insertion time, which gives the same order mongo
+	// returns documents in before sorting
+	slices.SortFunc(lectures, func(a, b Lecture) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	sortLectures(lectures)
+	return lectures, nil
+}
+
+func (m *MemDb) DeleteLecture(lectureID string) error {
+	ID, err := primitive.ObjectIDFromHex(lectureID)
+	if err != nil {
+		return fmt.Errorf("error converting ObjectID from Hex: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.lectures[ID]; !ok {
+		return fmt.Errorf("lecture [ %s ] not found", lectureID)
+	}
+	if err := remove(m, m.lectures, ID); err != nil {
+		return fmt.Errorf("error deleting lecture: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) ImportLectures(lectures []Lecture) error {
+	for i := range lectures {
+		if err := validateWeek(lectures[i].Week); err != nil {
+			return err
+		}
+		if lectures[i].ID.IsZero() {
+			lectures[i].ID = primitive.NewObjectID()
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	next := maps.Clone(m.lectures)
+	for _, lecture := range lectures {
+		next[lecture.ID] = lecture
+	}
+	if err := replace(m, &m.lectures, next); err != nil {
+		return fmt.Errorf("error importing lectures: %w", err)
+	}
+	log.Printf("imported %v lectures\n", len(lectures))
+	return nil
+}
@@ -0,0 +1,89 @@
+package mdb
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemDbLectures(t *testing.T) {
+	db := NewMemDb()
+	lectures := []Lecture{
+		{Week: "0", Day: 1, Time: 1, Subject: "ОМО", Room: "101", SubGroup: "0", Group: "a"},
+		{Week: "2", Day: 1, Time: 2, Subject: "ТЭ", Room: "102", SubGroup: "1", Group: "a"},
+		{Week: "1", Day: 3, Time: 1, Subject: "ФК", Room: "103", SubGroup: "2", Group: "a"},
+		{Week: "0", Day: 1, Time: 1, Subject: "АЯ", Room: "104", SubGroup: "0", Group: "b"},
+	}
+	for _, l := range lectures {
+		if err := db.InsertLecture(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.InsertLecture(Lecture{Week: "5", Group: "a"}); err == nil {
+		t.Error("inserted a lecture of week 5")
+	}
+
+	tests := []struct {
+		name   string
+		filter bson.M
+		want   []string
+	}{
+		{"group", LectureQuery{Group: "a"}.Filter(), []string{"ОМО", "ФК", "ТЭ"}},
+		{"day", LectureQuery{Group: "a", Day: 1}.Filter(), []string{"ОМО", "ТЭ"}},
+		{"week with every week", LectureQuery{Group: "a", Week: 2}.Filter(), []string{"ОМО", "ТЭ"}},
+		{"subgroup with the whole group", LectureQuery{Group: "a", SubGroup: "2"}.Filter(), []string{"ОМО", "ФК"}},
+		{"subject", LectureQuery{Group: "b", Subject: "АЯ"}.Filter(), []string{"АЯ"}},
+		{"nothing", LectureQuery{Group: "c"}.Filter(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.GetLectures(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var subjects []string
+			for _, l := range got {
+				subjects = append(subjects, l.Subject)
+			}
+			if !slices.Equal(subjects, tt.want) {
+				t.Errorf("got %v, want %v", subjects, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemDbLectureChanges(t *testing.T) {
+	db := NewMemDb()
+	l := Lecture{ID: primitive.NewObjectID(), Week: "0", Day: 1, Time: 1, Subject: "ОМО", Room: "101", Group: "a"}
+	if err := db.InsertLecture(l); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.InsertLecture(l); err == nil {
+		t.Error("inserted a lecture twice")
+	}
+	l.Room = "202"
+	if err := db.UpdateLecture(l.ID, l); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetLecture(l.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Room != "202" {
+		t.Errorf("room %q after the update, want 202", got.Room)
+	}
+	if err := db.UpdateLecture(primitive.NewObjectID(), l); err == nil {
+		t.Error("updated a lecture that is not stored")
+	}
+	if err := db.DeleteLecture(l.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetLecture(l.ID.Hex()); err == nil {
+		t.Error("got a deleted lecture")
+	}
+	if err := db.DeleteLecture(l.ID.Hex()); err == nil {
+		t.Error("deleted a lecture twice")
+	}
+}
@@ -0,0 +1,204 @@
+package mdb
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OverrideKind string
+
+const (
+	// the lecture does not take place on the date
+	OverrideCancel OverrideKind = "cancel"
+	// the lecture takes place in another room or with another lecturer
+	OverrideChange OverrideKind = "change"
+	// a lecture that only takes place on the date
+	OverrideExtra OverrideKind = "extra"
+)
+
+// Override changes the timetable for a single date
+type Override struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Group     string             `bson:"group"`
+	Date      string             `bson:"date"`
+	Kind      OverrideKind       `bson:"kind"`
+	LectureID primitive.ObjectID `bson:"lecture_id,omitempty"`
+	Room      string             `bson:"room,omitempty"`
+	Lecturer  string             `bson:"lecturer,omitempty"`
+	Lecture   Lecture            `bson:"lecture"`
+}
+
+// DateLayout is the layout dates of overrides are stored in
+const DateLayout = "2006-01-02"
+
+func validateOverride(o Override) error {
+	if _, err := time.Parse(DateLayout, o.Date); err != nil {
+		return fmt.Errorf("error: date must be in YYYY-MM-DD format")
+	}
+	if o.Group == "" {
+		return fmt.Errorf("error: override needs a group")
+	}
+	switch o.Kind {
+	case OverrideCancel:
+		if o.LectureID.IsZero() {
+			return fmt.Errorf("error: cancel needs a lecture")
+		}
+	case OverrideChange:
+		if o.LectureID.IsZero() {
+			return fmt.Errorf("error: change needs a lecture")
+		}
+		if o.Room == "" && o.Lecturer == "" {
+			return fmt.Errorf("error: change needs a room or a lecturer")
+		}
+	case OverrideExtra:
+		if o.Lecture.Subject == "" || o.Lecture.Time == 0 {
+			return fmt.Errorf("error: one-off lecture needs a subject and a period")
+		}
+	default:
+		return fmt.Errorf("error: unknown override kind %q", o.Kind)
+	}
+	return nil
+}
+
+// ApplyOverrides merges the overrides of one date into the recurring
+// lectures of that date. cancelled lectures are kept and marked so they can
+// be shown as cancelled. one-off lectures are only added when they are for
+// subGroup, an empty subGroup means every subgroup
+func ApplyOverrides(lectures []Lecture, overrides []Override, subGroup string) []Lecture {
+	out := make([]Lecture, len(lectures))
+	copy(out, lectures)
+	index := make(map[primitive.ObjectID]int, len(out))
+	for i, lecture := range out {
+		index[lecture.ID] = i
+	}
+	for _, o := range overrides {
+		switch o.Kind {
+		case OverrideCancel:
+			if i, ok := index[o.LectureID]; ok {
+				out[i].Status = StatusCancelled
+			}
+		case OverrideChange:
+			if i, ok := index[o.LectureID]; ok {
+				if o.Room != "" {
+					out[i].Room = o.Room
+				}
+				if o.Lecturer != "" {
+					out[i].Lecturer = o.Lecturer
+				}
+				if out[i].Status != StatusCancelled {
+					out[i].Status = StatusChanged
+				}
+			}
+		case OverrideExtra:
+			lecture := o.Lecture
+			if subGroup != "" && lecture.SubGroup != subGroup && lecture.SubGroup != "0" {
+				continue
+			}
+			lecture.ID = o.ID
+			lecture.Status = StatusExtra
+			out = append(out, lecture)
+		}
+	}
+	sortLectures(out)
+	return out
+}
+
+func (d *Db) InsertOverride(o Override) error {
+	if err := validateOverride(o); err != nil {
+		return err
+	}
+	result, err := d.OverrideCollection.InsertOne(context.TODO(), o)
+	if err != nil {
+		return fmt.Errorf("error inserting override: %w", err)
+	}
+	log.Printf("Inserted override with ID: %v\n", result.InsertedID)
+	return nil
+}
+
+func (d *Db) GetOverrides(filter bson.M) ([]Override, error) {
+	var overrides []Override
+	if err := findAll(d.OverrideCollection, filter, &overrides); err != nil {
+		return nil, fmt.Errorf("error getting overrides: %w", err)
+	}
+	sortOverrides(overrides)
+	return overrides, nil
+}
+
+func (d *Db) DeleteOverride(overrideID string) error {
+	ID, err := primitive.ObjectIDFromHex(overrideID)
+	if err != nil {
+		return fmt.Errorf("error converting ObjectID from Hex: %w", err)
+	}
+	result, err := d.OverrideCollection.DeleteOne(context.TODO(), bson.M{"_id": ID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("override [ %s ] not found", overrideID)
+	}
+	return nil
+}
+
+func (m *MemDb) InsertOverride(o Override) error {
+	if err := validateOverride(o); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o.ID.IsZero() {
+		o.ID = primitive.NewObjectID()
+	}
+	if err := put(m, m.overrides, o.ID, o); err != nil {
+		return fmt.Errorf("error inserting override: %w", err)
+	}
+	log.Printf("Inserted override with ID: %v\n", o.ID)
+	return nil
+}
+
+func (m *MemDb) GetOverrides(filter bson.M) ([]Override, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var overrides []Override
+	for _, o := range m.overrides {
+		doc, err := toDoc(o)
+		if err != nil {
+			return nil, fmt.Errorf("error getting overrides: %w", err)
+		}
+		if matches(doc, filter) {
+			overrides = append(overrides, o)
+		}
+	}
+	sortOverrides(overrides)
+	return overrides, nil
+}
+
+func (m *MemDb) DeleteOverride(overrideID string) error {
+	ID, err := primitive.ObjectIDFromHex(overrideID)
+	if err != nil {
+		return fmt.Errorf("error converting ObjectID from Hex: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.overrides[ID]; !ok {
+		return fmt.Errorf("override [ %s ] not found", overrideID)
+	}
+	if err := remove(m, m.overrides, ID); err != nil {
+		return fmt.Errorf("error deleting override: %w", err)
+	}
+	return nil
+}
+
+// orders overrides by date, then by the order they were made in
+func sortOverrides(overrides []Override) {
+	slices.SortFunc(overrides, func(a, b Override) int {
+		return cmp.Or(cmp.Compare(a.Date, b.Date), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+}
@@ -0,0 +1,71 @@
+package mdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Prefs are the defaults a user saved with /settings
+type Prefs struct {
+	UserID int64 `bson:"_id"`
+	// the subgroup shown when no flag is given, "" for all of them
+	SubGroup string `bson:"sub_group"`
+	Long     bool   `bson:"long"`
+	Language string `bson:"language"`
+	// delete the timetable messages sent to the user after a while
+	AutoDelete bool `bson:"auto_delete"`
+}
+
+// DefaultPrefs are the preferences of a user who never changed them
+func DefaultPrefs(userID int64) Prefs {
+	return Prefs{UserID: userID, SubGroup: "1", Language: "ru", AutoDelete: true}
+}
+
+// Args are the display options the preferences stand for
+func (p Prefs) Args() Args {
+	return Args{Long: p.Long, Group: p.SubGroup}
+}
+
+func (d *Db) SavePrefs(p Prefs) error {
+	opts := options.Replace().SetUpsert(true)
+	if _, err := d.PrefsCollection.ReplaceOne(context.TODO(), bson.M{"_id": p.UserID}, p, opts); err != nil {
+		return fmt.Errorf("error saving preferences: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetPrefs(userID int64) (Prefs, error) {
+	var p Prefs
+	err := d.PrefsCollection.FindOne(context.TODO(), bson.M{"_id": userID}).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Prefs{}, fmt.Errorf("preferences of %d: %w", userID, ErrNotFound)
+	}
+	if err != nil {
+		return Prefs{}, fmt.Errorf("error getting preferences: %w", err)
+	}
+	return p, nil
+}
+
+func (m *MemDb) SavePrefs(p Prefs) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := put(m, m.prefs, p.UserID, p); err != nil {
+		return fmt.Errorf("error saving preferences: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetPrefs(userID int64) (Prefs, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.prefs[userID]
+	if !ok {
+		return Prefs{}, fmt.Errorf("preferences of %d: %w", userID, ErrNotFound)
+	}
+	return p, nil
+}
@@ -0,0 +1,226 @@
+package mdb
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// roles a user can be granted, each one can do everything the ones before it
+// can
+const (
+	// RoleEditor manages the overrides of a group
+	RoleEditor = "editor"
+	// RoleAdmin manages the lectures and subjects of a group
+	RoleAdmin = "admin"
+	// RoleOwner manages every group and grants roles
+	RoleOwner = "owner"
+)
+
+// Roles lists the roles from the least to the most powerful
+var Roles = []string{RoleEditor, RoleAdmin, RoleOwner}
+
+// RoleRank orders the roles, users without a role rank 0
+func RoleRank(role string) int {
+	return slices.Index(Roles, role) + 1
+}
+
+// Grant gives a user a role in a group. owners hold their role in every
+// group, so their grant has no group
+type Grant struct {
+	ID        string    `bson:"_id"`
+	UserID    int64     `bson:"user_id"`
+	Group     string    `bson:"group"`
+	Role      string    `bson:"role"`
+	GrantedBy int64     `bson:"granted_by"`
+	Time      time.Time `bson:"time"`
+}
+
+// a user has at most one role in each group
+func GrantID(userID int64, group string) string {
+	return fmt.Sprintf("%d/%s", userID, group)
+}
+
+func validateGrant(g Grant) error {
+	if RoleRank(g.Role) == 0 {
+		return fmt.Errorf("error: unknown role %q, must be one of %v", g.Role, Roles)
+	}
+	if (g.Role == RoleOwner) != (g.Group == "") {
+		return fmt.Errorf("error: owners are granted for every group, other roles for one group")
+	}
+	return nil
+}
+
+func (d *Db) SaveGrant(g Grant) error {
+	if err := validateGrant(g); err != nil {
+		return err
+	}
+	g.ID = GrantID(g.UserID, g.Group)
+	opts := options.Replace().SetUpsert(true)
+	if _, err := d.RoleCollection.ReplaceOne(context.TODO(), bson.M{"_id": g.ID}, g, opts); err != nil {
+		return fmt.Errorf("error saving grant: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) DeleteGrant(userID int64, group string) error {
+	result, err := d.RoleCollection.DeleteOne(context.TODO(), bson.M{"_id": GrantID(userID, group)})
+	if err != nil {
+		return fmt.Errorf("error deleting grant: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("grant: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (d *Db) GetGrants(filter bson.M) ([]Grant, error) {
+	var grants []Grant
+	if err := findAll(d.RoleCollection, filter, &grants); err != nil {
+		return nil, fmt.Errorf("error getting grants: %w", err)
+	}
+	sortGrants(grants)
+	return grants, nil
+}
+
+func (m *MemDb) SaveGrant(g Grant) error {
+	if err := validateGrant(g); err != nil {
+		return err
+	}
+	g.ID = GrantID(g.UserID, g.Group)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := put(m, m.roles, g.ID, g); err != nil {
+		return fmt.Errorf("error saving grant: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) DeleteGrant(userID int64, group string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := GrantID(userID, group)
+	if _, ok := m.roles[id]; !ok {
+		return fmt.Errorf("grant: %w", ErrNotFound)
+	}
+	if err := remove(m, m.roles, id); err != nil {
+		return fmt.Errorf("error deleting grant: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetGrants(filter bson.M) ([]Grant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var grants []Grant
+	for _, g := range m.roles {
+		doc, err := toDoc(g)
+		if err != nil {
+			return nil, fmt.Errorf("error getting grants: %w", err)
+		}
+		if matches(doc, filter) {
+			grants = append(grants, g)
+		}
+	}
+	sortGrants(grants)
+	return grants, nil
+}
+
+// orders grants by group, the owners first, then by user
+func sortGrants(grants []Grant) {
+	slices.SortFunc(grants, func(a, b Grant) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.UserID, b.UserID))
+	})
+}
+
+// the most powerful role the user holds in the group, "" for none
+func UserRole(db RoleStore, userID int64, group string) (string, error) {
+	grants, err := db.GetGrants(bson.M{"user_id": userID, "group": bson.M{"$in": []string{"", group}}})
+	if err != nil {
+		return "", err
+	}
+	role := ""
+	for _, g := range grants {
+		if RoleRank(g.Role) > RoleRank(role) {
+			role = g.Role
+		}
+	}
+	return role, nil
+}
+
+// ErrLastOwner refuses to revoke the owner role of the only owner, the bot
+// would be left without anyone to grant roles
+var ErrLastOwner = errors.New("the last owner can't be revoked")
+
+// RevokeGrant deletes the grant of the user in the group, unless it is the
+// last owner grant. every revoke goes through it, /revoke and /undo alike
+func RevokeGrant(db RoleStore, userID int64, group string) error {
+	if group == "" {
+		owners, err := db.GetGrants(bson.M{"role": RoleOwner})
+		if err != nil {
+			return err
+		}
+		others := slices.DeleteFunc(owners, func(g Grant) bool { return g.UserID == userID })
+		if len(others) == 0 {
+			return ErrLastOwner
+		}
+	}
+	return db.DeleteGrant(userID, group)
+}
+
+// makes the users owners when nobody owns the bot yet, so the first owner
+// can come from the configuration and later ones are granted from the bot
+func SeedOwners(db RoleStore, userIDs []int64) error {
+	owners, err := db.GetGrants(bson.M{"role": RoleOwner})
+	if err != nil {
+		return err
+	}
+	if len(owners) > 0 {
+		return nil
+	}
+	for _, userID := range userIDs {
+		if err := db.SaveGrant(Grant{UserID: userID, Role: RoleOwner, Time: time.Now()}); err != nil {
+			return err
+		}
+		log.Printf("seeded owner %d", userID)
+	}
+	return nil
+}
+
+// turns the admins stored in the groups before roles existed into grants
+func MigrateGroupAdmins(db Store) error {
+	groups, err := db.GetGroups()
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		if len(group.Admins) == 0 {
+			continue
+		}
+		for _, userID := range group.Admins {
+			role, err := UserRole(db, userID, group.Key)
+			if err != nil {
+				return err
+			}
+			if RoleRank(role) >= RoleRank(RoleAdmin) {
+				continue
+			}
+			if err := db.SaveGrant(Grant{UserID: userID, Group: group.Key, Role: RoleAdmin, Time: time.Now()}); err != nil {
+				return err
+			}
+		}
+		log.Printf("moved admins of group %v to grants: %v", group.Key, group.Admins)
+		group.Admins = nil
+		if err := db.UpdateGroup(group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
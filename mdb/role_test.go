@@ -0,0 +1,45 @@
+package mdb
+
+import "testing"
+
+func TestUserRole(t *testing.T) {
+	db := NewMemDb()
+	grants := []Grant{
+		{UserID: 1, Role: RoleOwner},
+		{UserID: 2, Group: "a", Role: RoleAdmin},
+		{UserID: 2, Group: "b", Role: RoleEditor},
+		{UserID: 3, Group: "a", Role: RoleEditor},
+		{UserID: 1, Group: "a", Role: RoleEditor},
+	}
+	for _, g := range grants {
+		if err := db.SaveGrant(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		userID int64
+		group  string
+		want   string
+	}{
+		{1, "a", RoleOwner},
+		{1, "c", RoleOwner},
+		{2, "a", RoleAdmin},
+		{2, "b", RoleEditor},
+		{2, "c", ""},
+		{3, "a", RoleEditor},
+		{3, "b", ""},
+		{4, "a", ""},
+	}
+	for _, tt := range tests {
+		got, err := UserRole(db, tt.userID, tt.group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("UserRole(%d, %q) = %q, want %q", tt.userID, tt.group, got, tt.want)
+		}
+	}
+	if RoleRank(RoleOwner) <= RoleRank(RoleAdmin) || RoleRank(RoleAdmin) <= RoleRank(RoleEditor) || RoleRank(RoleEditor) <= RoleRank("") {
+		t.Error("roles are not ranked owner > admin > editor > none")
+	}
+}
@@ -0,0 +1,132 @@
+package mdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// wizards a session can belong to
+const (
+	SessionAddLecture    = "addlecture"
+	SessionEditLecture   = "editlecture"
+	SessionDeleteLecture = "deletelecture"
+	// an import of lectures waiting for its file or for the admin to
+	// confirm it
+	SessionImportLectures = "importlectures"
+)
+
+// Session is the state of a wizard a user runs in a chat. a user has at
+// most one session per chat
+type Session struct {
+	ID     string `bson:"_id"`
+	ChatID int64  `bson:"chat_id"`
+	UserID int64  `bson:"user_id"`
+	Kind   string `bson:"kind"`
+	// the index of the wizard step waiting for an answer
+	Step int `bson:"step"`
+	// the lecture being edited and the lecture being entered
+	OldLecture Lecture `bson:"old_lecture"`
+	NewLecture Lecture `bson:"new_lecture"`
+	// the admin chose to save the lecture despite its clashes
+	Force bool `bson:"force,omitempty"`
+	// the lectures of an uploaded file waiting to be imported, and how many
+	// of its rows clash with other lectures. they are imported only when
+	// the admin replies force
+	Lectures []Lecture `bson:"lectures,omitempty"`
+	Clashing int       `bson:"clashing,omitempty"`
+	Updated  time.Time `bson:"updated"`
+}
+
+func SessionID(chatID, userID int64) string {
+	return fmt.Sprintf("%d/%d", chatID, userID)
+}
+
+func (d *Db) SaveSession(s Session) error {
+	s.ID = SessionID(s.ChatID, s.UserID)
+	opts := options.Replace().SetUpsert(true)
+	if _, err := d.SessionCollection.ReplaceOne(context.TODO(), bson.M{"_id": s.ID}, s, opts); err != nil {
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetSession(chatID, userID int64) (Session, error) {
+	var s Session
+	err := d.SessionCollection.FindOne(context.TODO(), bson.M{"_id": SessionID(chatID, userID)}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Session{}, fmt.Errorf("session: %w", ErrNotFound)
+	}
+	if err != nil {
+		return Session{}, fmt.Errorf("error getting session: %w", err)
+	}
+	return s, nil
+}
+
+func (d *Db) DeleteSession(chatID, userID int64) error {
+	if _, err := d.SessionCollection.DeleteOne(context.TODO(), bson.M{"_id": SessionID(chatID, userID)}); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) DeleteSessionsBefore(t time.Time) (int, error) {
+	result, err := d.SessionCollection.DeleteMany(context.TODO(), bson.M{"updated": bson.M{"$lt": t}})
+	if err != nil {
+		return 0, fmt.Errorf("error deleting sessions: %w", err)
+	}
+	return int(result.DeletedCount), nil
+}
+
+func (m *MemDb) SaveSession(s Session) error {
+	s.ID = SessionID(s.ChatID, s.UserID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := put(m, m.sessions, s.ID, s); err != nil {
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetSession(chatID, userID int64) (Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[SessionID(chatID, userID)]
+	if !ok {
+		return Session{}, fmt.Errorf("session: %w", ErrNotFound)
+	}
+	return s, nil
+}
+
+func (m *MemDb) DeleteSession(chatID, userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := remove(m, m.sessions, SessionID(chatID, userID)); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) DeleteSessionsBefore(t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	next := make(map[string]Session, len(m.sessions))
+	for id, s := range m.sessions {
+		if !s.Updated.Before(t) {
+			next[id] = s
+		}
+	}
+	count := len(m.sessions) - len(next)
+	if count == 0 {
+		return 0, nil
+	}
+	if err := replace(m, &m.sessions, next); err != nil {
+		return 0, fmt.Errorf("error deleting sessions: %w", err)
+	}
+	return count, nil
+}
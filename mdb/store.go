@@ -0,0 +1,116 @@
+package mdb
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound is returned when a lookup by key finds nothing
+var ErrNotFound = errors.New("not found")
+
+// LectureStore is implemented by every storage backend the bot can run on
+type LectureStore interface {
+	InsertLecture(lecture Lecture) error
+	UpdateLecture(ID primitive.ObjectID, lecture Lecture) error
+	GetLecture(lectureID string) (Lecture, error)
+	GetLectures(filter bson.M) ([]Lecture, error)
+	DeleteLecture(lectureID string) error
+	ImportLectures(lectures []Lecture) error
+	// sets the group of lectures stored before groups existed
+	AssignLectureGroup(group string) (int, error)
+}
+
+type GroupStore interface {
+	InsertGroup(group Group) error
+	UpdateGroup(group Group) error
+	GetGroup(key string) (Group, error)
+	GetGroups() ([]Group, error)
+}
+
+type ChatStore interface {
+	SaveChat(chat Chat) error
+	GetChat(chatID int64) (Chat, error)
+	GetChats(filter bson.M) ([]Chat, error)
+}
+
+type CatalogStore interface {
+	InsertSubject(subject Subject) error
+	UpdateSubject(subject Subject) error
+	DeleteSubject(group, key string) error
+	GetSubjects(group string) (map[string]Subject, error)
+	SetPeriods(periods map[int]Period) error
+	GetPeriods() (map[int]Period, error)
+	SetDays(days map[int]string) error
+	GetDays() (map[int]string, error)
+	SetTypes(types map[string]int) error
+	GetTypes() (map[string]int, error)
+}
+
+type OverrideStore interface {
+	InsertOverride(o Override) error
+	GetOverrides(filter bson.M) ([]Override, error)
+	DeleteOverride(overrideID string) error
+}
+
+type TokenStore interface {
+	InsertToken(t Token) error
+	GetToken(token string) (Token, error)
+	DeleteUserTokens(userID int64, kind string) (int, error)
+}
+
+type SubscriptionStore interface {
+	SaveSubscription(s Subscription) error
+	DeleteSubscription(chatID int64, kind string) error
+	GetSubscriptions(filter bson.M) ([]Subscription, error)
+}
+
+type SessionStore interface {
+	SaveSession(s Session) error
+	GetSession(chatID, userID int64) (Session, error)
+	DeleteSession(chatID, userID int64) error
+	DeleteSessionsBefore(t time.Time) (int, error)
+}
+
+type PrefsStore interface {
+	SavePrefs(p Prefs) error
+	GetPrefs(userID int64) (Prefs, error)
+}
+
+// AuditStore keeps the log of changes made by admins
+type AuditStore interface {
+	InsertAudit(e AuditEntry) error
+	// entries matching the filter, newest first. a limit of 0 returns all
+	GetAudit(filter bson.M, limit int) ([]AuditEntry, error)
+	SetAuditUndone(id primitive.ObjectID) error
+}
+
+// RoleStore keeps the roles granted to users
+type RoleStore interface {
+	SaveGrant(g Grant) error
+	DeleteGrant(userID int64, group string) error
+	GetGrants(filter bson.M) ([]Grant, error)
+}
+
+// Store is everything the bot needs from a backend
+type Store interface {
+	LectureStore
+	GroupStore
+	ChatStore
+	CatalogStore
+	OverrideStore
+	TokenStore
+	SubscriptionStore
+	SessionStore
+	PrefsStore
+	AuditStore
+	RoleStore
+}
+
+var (
+	_ Store = (*Db)(nil)
+	_ Store = (*MemDb)(nil)
+	_ Store = (*Audited)(nil)
+)
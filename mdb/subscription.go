@@ -0,0 +1,107 @@
+package mdb
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// SubscriptionReminder subscriptions are reminded before each lecture
+	SubscriptionReminder = "reminder"
+	// SubscriptionDigest subscriptions get the timetable of the next day
+	SubscriptionDigest = "digest"
+)
+
+// Subscription is a chat that asked the bot to message it on its own
+type Subscription struct {
+	ID       string `bson:"_id"`
+	ChatID   int64  `bson:"chat_id"`
+	Kind     string `bson:"kind"`
+	Group    string `bson:"group"`
+	SubGroup string `bson:"sub_group"`
+	// reminders: how many minutes before a lecture to remind
+	Minutes int `bson:"minutes,omitempty"`
+	// digests: local time to send at as "HH:MM", whether to use the long
+	// format and the date of the last digest sent
+	Time     string `bson:"time,omitempty"`
+	Long     bool   `bson:"long,omitempty"`
+	LastSent string `bson:"last_sent,omitempty"`
+}
+
+// a chat has at most one subscription of each kind
+func SubscriptionID(chatID int64, kind string) string {
+	return fmt.Sprintf("%d/%s", chatID, kind)
+}
+
+func (d *Db) SaveSubscription(s Subscription) error {
+	s.ID = SubscriptionID(s.ChatID, s.Kind)
+	opts := options.Replace().SetUpsert(true)
+	if _, err := d.SubscriptionCollection.ReplaceOne(context.TODO(), bson.M{"_id": s.ID}, s, opts); err != nil {
+		return fmt.Errorf("error saving subscription: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) DeleteSubscription(chatID int64, kind string) error {
+	result, err := d.SubscriptionCollection.DeleteOne(context.TODO(), bson.M{"_id": SubscriptionID(chatID, kind)})
+	if err != nil {
+		return fmt.Errorf("error deleting subscription: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("subscription: %w", ErrNotFound)
+	}
+	return nil
+}
+
+func (d *Db) GetSubscriptions(filter bson.M) ([]Subscription, error) {
+	var subscriptions []Subscription
+	if err := findAll(d.SubscriptionCollection, filter, &subscriptions); err != nil {
+		return nil, fmt.Errorf("error getting subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (m *MemDb) SaveSubscription(s Subscription) error {
+	s.ID = SubscriptionID(s.ChatID, s.Kind)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := put(m, m.subscriptions, s.ID, s); err != nil {
+		return fmt.Errorf("error saving subscription: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) DeleteSubscription(chatID int64, kind string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := SubscriptionID(chatID, kind)
+	if _, ok := m.subscriptions[id]; !ok {
+		return fmt.Errorf("subscription: %w", ErrNotFound)
+	}
+	if err := remove(m, m.subscriptions, id); err != nil {
+		return fmt.Errorf("error deleting subscription: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetSubscriptions(filter bson.M) ([]Subscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var subscriptions []Subscription
+	for _, s := range m.subscriptions {
+		doc, err := toDoc(s)
+		if err != nil {
+			return nil, fmt.Errorf("error getting subscriptions: %w", err)
+		}
+		if matches(doc, filter) {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	slices.SortFunc(subscriptions, func(a, b Subscription) int { return cmp.Compare(a.ID, b.ID) })
+	return subscriptions, nil
+}
@@ -0,0 +1,92 @@
+package mdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// TokenCalendar tokens give read access to the calendar feed of a subgroup
+	TokenCalendar = "calendar"
+	// TokenAPI tokens let an admin manage the lectures of a group over http
+	TokenAPI = "api"
+)
+
+// Token is a secret handed to a user for access outside of telegram
+type Token struct {
+	Token    string    `bson:"_id"`
+	Kind     string    `bson:"kind"`
+	UserID   int64     `bson:"user_id"`
+	Group    string    `bson:"group"`
+	SubGroup string    `bson:"sub_group"`
+	Created  time.Time `bson:"created"`
+}
+
+func (d *Db) InsertToken(t Token) error {
+	if _, err := d.TokenCollection.InsertOne(context.TODO(), t); err != nil {
+		return fmt.Errorf("error inserting token: %w", err)
+	}
+	return nil
+}
+
+func (d *Db) GetToken(token string) (Token, error) {
+	var t Token
+	err := d.TokenCollection.FindOne(context.TODO(), bson.M{"_id": token}).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Token{}, fmt.Errorf("token: %w", ErrNotFound)
+	}
+	if err != nil {
+		return Token{}, fmt.Errorf("error getting token: %w", err)
+	}
+	return t, nil
+}
+
+func (d *Db) DeleteUserTokens(userID int64, kind string) (int, error) {
+	result, err := d.TokenCollection.DeleteMany(context.TODO(), bson.M{"user_id": userID, "kind": kind})
+	if err != nil {
+		return 0, fmt.Errorf("error deleting tokens: %w", err)
+	}
+	return int(result.DeletedCount), nil
+}
+
+func (m *MemDb) InsertToken(t Token) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tokens[t.Token]; ok {
+		return fmt.Errorf("error inserting token: duplicate token")
+	}
+	if err := put(m, m.tokens, t.Token, t); err != nil {
+		return fmt.Errorf("error inserting token: %w", err)
+	}
+	return nil
+}
+
+func (m *MemDb) GetToken(token string) (Token, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.tokens[token]
+	if !ok {
+		return Token{}, fmt.Errorf("token: %w", ErrNotFound)
+	}
+	return t, nil
+}
+
+func (m *MemDb) DeleteUserTokens(userID int64, kind string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for key, t := range m.tokens {
+		if t.UserID == userID && t.Kind == kind {
+			if err := remove(m, m.tokens, key); err != nil {
+				return count, fmt.Errorf("error deleting tokens: %w", err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
@@ -17,27 +17,89 @@ type Lecture struct {
 	Room     string             `bson:"room"`
 	Lecturer string             `bson:"lecturer"`
 	SubGroup string             `bson:"sub_group"`
+	Group    string             `bson:"group"`
+	// how an override changed the lecture on a date, never stored
+	Status LectureStatus `bson:"-" json:"-"`
+}
+
+type LectureStatus string
+
+const (
+	StatusCancelled LectureStatus = "cancelled"
+	StatusChanged   LectureStatus = "changed"
+	StatusExtra     LectureStatus = "extra"
+)
+
+// Group is a study group served by the bot, each with its own timetable
+type Group struct {
+	Key  string `bson:"_id"`
+	Name string `bson:"name"`
+	// admins stored before roles existed, moved to grants on start
+	Admins []int64 `bson:"admins,omitempty"`
+}
+
+// Chat remembers which group a telegram chat is looking at. every chat that
+// used the bot is registered, so it can be told about timetable changes
+type Chat struct {
+	ID    int64  `bson:"_id"`
+	Group string `bson:"group"`
+	// the subgroup the chat last asked for, "" for all of them
+	SubGroup string `bson:"sub_group"`
+	// the chat opted out of change notifications
+	Muted bool `bson:"muted"`
+	// the language chosen for the chat, "" to use the user's
+	Language string `bson:"language,omitempty"`
 }
 
 type Db struct {
-	LectureCollection *mongo.Collection
+	LectureCollection      *mongo.Collection
+	GroupCollection        *mongo.Collection
+	ChatCollection         *mongo.Collection
+	SubjectCollection      *mongo.Collection
+	PeriodCollection       *mongo.Collection
+	DayCollection          *mongo.Collection
+	TypeCollection         *mongo.Collection
+	OverrideCollection     *mongo.Collection
+	TokenCollection        *mongo.Collection
+	SubscriptionCollection *mongo.Collection
+	SessionCollection      *mongo.Collection
+	PrefsCollection        *mongo.Collection
+	AuditCollection        *mongo.Collection
+	RoleCollection         *mongo.Collection
 }
 
 type Subject struct {
-	Name     string
-	Key      string
-	Lecturer string
+	Name     string `bson:"name"`
+	Key      string `bson:"key"`
+	Lecturer string `bson:"lecturer"`
+	Group    string `bson:"group"`
+	// translations of the name by language
+	Names map[string]string `bson:"names,omitempty"`
 }
 type Period struct {
-	start string
-	end   string
+	Number int    `bson:"_id"`
+	Start  string `bson:"start"`
+	End    string `bson:"end"`
 }
 
 func (p Period) String() string {
-	return fmt.Sprintf("%s-%s", p.start, p.end)
+	return fmt.Sprintf("%s-%s", p.Start, p.End)
 }
 
-var Subjects = map[string]Subject{
+type Day struct {
+	Number int    `bson:"_id"`
+	Name   string `bson:"name"`
+}
+
+type LectureType struct {
+	Key   string `bson:"_id"`
+	Order int    `bson:"order"`
+}
+
+// the values below seed the catalog on the first run, after that subjects,
+// periods, days and types are managed through the bot
+
+var DefaultSubjects = map[string]Subject{
 	"ТЭ":    {Name: "Техническая Электроника", Key: "ТЭ", Lecturer: "Половеня С.И"},
 	"ОИкТ":  {Name: "Основы Инфокоммуникационных Технологий", Key: "ОИкТ", Lecturer: "Дулькевич А.И"},
 	"ФК":    {Name: "Физическая Культура", Key: "ФК", Lecturer: "Байко О.М"},
@@ -51,16 +113,16 @@ var Subjects = map[string]Subject{
 	"ОМО":   {Name: "Основы Машинного Обучения", Key: "ОМО", Lecturer: "Колодный В.Б"},
 }
 
-var Periods = map[int]Period{
-	1: {start: "8:00", end: "9:40"},
-	2: {start: "9:55", end: "11:35"},
-	3: {start: "12:15", end: "13:55"},
-	4: {start: "14:10", end: "15:50"},
-	5: {start: "16:20", end: "18:00"},
-	6: {start: "18:15", end: "19:55"},
+var DefaultPeriods = map[int]Period{
+	1: {Number: 1, Start: "8:00", End: "9:40"},
+	2: {Number: 2, Start: "9:55", End: "11:35"},
+	3: {Number: 3, Start: "12:15", End: "13:55"},
+	4: {Number: 4, Start: "14:10", End: "15:50"},
+	5: {Number: 5, Start: "16:20", End: "18:00"},
+	6: {Number: 6, Start: "18:15", End: "19:55"},
 }
 
-var Days = map[int]string{
+var DefaultDays = map[int]string{
 	1: "Понедельник",
 	2: "Вторник",
 	3: "Среда",
@@ -68,12 +130,16 @@ var Days = map[int]string{
 	5: "Пятница",
 	6: "Суббота",
 }
-var Types = map[string]int{
+var DefaultTypes = map[string]int{
 	"ЛР": 1,
 	"ПЗ": 2,
 	"ЛК": 4,
 	"-":  5,
 }
+
+// the number of weeks after which the timetable repeats
+const CycleWeeks = 4
+
 var Weeks = map[string]int{
 	"0": 1,
 	"1": 2,
@@ -90,6 +156,10 @@ var SubGroup = map[string]int{
 type Args struct {
 	Long  bool
 	Group string
+	// key of the study group, not to be confused with the subgroup above
+	StudyGroup string
+	// language of the rendered timetable
+	Lang string
 }
 
 var CmdOpts = map[string]int{
@@ -97,4 +167,5 @@ var CmdOpts = map[string]int{
 	"-1":   2,
 	"-2":   3,
 	"-all": 4,
+	"-s":   5,
 }
@@ -0,0 +1,288 @@
+package main
+
+// belarusian messages
+var messagesBe = map[string]string{
+	"day.0":       "Нядзеля",
+	"day.1":       "Панядзелак",
+	"day.2":       "Аўторак",
+	"day.3":       "Серада",
+	"day.4":       "Чацвер",
+	"day.5":       "Пятніца",
+	"day.6":       "Субота",
+	"day.0.short": "нд",
+	"day.1.short": "пн",
+	"day.2.short": "аў",
+	"day.3.short": "ср",
+	"day.4.short": "чц",
+	"day.5.short": "пт",
+	"day.6.short": "сб",
+
+	"subgroup.all":         "усе падгрупы",
+	"subgroup.one":         "падгрупа %v",
+	"week":                 "тыдзень %v",
+	"no_lectures":          "заняткаў няма 🎊",
+	"no_lectures.tomorrow": "заўтра заняткаў няма 🎊",
+	"day_free":             "%v вольны🎊",
+	"lectures.one":         "%d пара",
+	"lectures.few":         "%d пары",
+	"lectures.many":        "%d пар",
+	"minutes.one":          "%d хвіліну",
+	"minutes.few":          "%d хвіліны",
+	"minutes.many":         "%d хвілін",
+	"hours.one":            "%d гадзіну",
+	"hours.few":            "%d гадзіны",
+	"hours.many":           "%d гадзін",
+	"on":                   "укл",
+	"off":                  "выкл",
+	"mode.long":            "падрабязна",
+	"mode.short":           "коратка",
+
+	"now.current": "*Зараз ідзе пара*, скончыцца праз %v, у %v",
+	"now.next":    "*Наступная пара* праз %v, у %v",
+	"now.break":   "Перапынак пасля %d-й пары, %d-я пара пачнецца праз %v, у %v",
+	"now.none":    "Зараз пар няма",
+	"now.done":    "Сёння больш пар няма 🎊",
+
+	"date.usage":     "/date 2026-11-03 або /date 03.11, +N зрушвае дату на N тыдняў: /date 03.11 +1",
+	"date.day_usage": "/day чацвер або /day чц, +N зрушвае дзень на N тыдняў: /day чц +1",
+
+	"weeks.which":      "Зараз %d-ы тыдзень з %d, %v–%v\nнаступны — %d-ы",
+	"weeks.week_usage": "/week N — расклад бліжэйшага N-га тыдня, N ад 1 да %d",
+	"weeks.usage":      "/weeks N — расклад на N тыдняў наперад, N ад 1 да %d",
+
+	"nav.day":  "дзень",
+	"nav.week": "тыдзень",
+
+	"settings":            "*Налады*\nпадгрупа: %v\nрэжым: %v\nмова: %v\nаўтавыдаленне паведамленняў: %v\n\n_сцягі -l, -1, -2, -all у камандах важнейшыя за налады_",
+	"settings.autodelete": "аўтавыдаленне: %v",
+	"settings.foreign":    "Гэта не вашы налады, адпраўце /settings",
+	"language.set":        "Мова: %v",
+	"language.usage":      "Мова: %v\n/language ru, en або be",
+
+	"notify.status.on":  "Апавяшчэнні пра змены ў раскладзе ўключаны\n/notify off выключае іх",
+	"notify.status.off": "Апавяшчэнні пра змены ў раскладзе выключаны\n/notify on уключае іх",
+	"notify.on":         "Апавяшчэнні пра змены ў раскладзе ўключаны",
+	"notify.off":        "Апавяшчэнні пра змены ў раскладзе выключаны",
+	"notify.changed":    "*Змена ў раскладзе*",
+	"notify.deleted":    "*Занятак выдалены з раскладу*",
+	"notify.footer":     "_/notify off выключае апавяшчэнні_",
+	"field.week":        "тыдзень",
+	"field.subject":     "прадмет",
+	"field.type":        "тып",
+	"field.day":         "дзень",
+	"field.period":      "пара",
+	"field.room":        "аўдыторыя",
+	"field.lecturer":    "выкладчык",
+	"field.subgroup":    "падгрупа",
+
+	"digest.status.off":  "Рассылка выключана\n/digest 20:00 -2 -l дасылае расклад на заўтра кожны дзень у 20:00",
+	"digest.status":      "Расклад на заўтра прыходзіць у %v, %v\n/digest off выключае рассылку",
+	"digest.already_off": "Рассылка ўжо выключана",
+	"digest.off":         "Рассылка выключана",
+	"digest.usage":       "/digest ГГ:ХХ [-1|-2|-all] [-l] або /digest off",
+	"digest.on":          "Расклад на заўтра будзе прыходзіць у %v, %v",
+
+	"remind":             "⏰ Праз %v: %v (%v)\nАўдыторыя: %v\n%v пара, %v",
+	"remind.changed":     "⚠️ змены на сёння",
+	"remind.status.off":  "Напаміны выключаны\n/remind on 15 -2 уключае напаміны за 15 хвілін да заняткаў падгрупы 2",
+	"remind.status":      "Напаміны за %v да заняткаў, %v\n/remind off выключае іх",
+	"remind.already_off": "Напаміны ўжо выключаны",
+	"remind.off":         "Напаміны выключаны",
+	"remind.range":       "Пазначце ад 1 да %v хвілін",
+	"remind.on":          "Напаміны ўключаны: за %v да заняткаў, %v",
+	"remind.usage":       "/remind on [хвіліны] [-1|-2|-all] або /remind off",
+
+	"calendar.private":      "Выкарыстоўвайце /calendar у асабістым чаце з ботам",
+	"calendar.none":         "У вас няма спасылкі на каляндар",
+	"calendar.revoked":      "Спасылка на каляндар адклікана",
+	"calendar.unconfigured": "Падпіска на каляндар не наладжана, выкарыстоўвайце /ics",
+	"calendar.link":         "Дадайце спасылку ў каляндар як падпіску:\n%v\n\nПапярэдняя спасылка больш не працуе. /calendar revoke адклікае спасылку",
+	"ics.name":              "Расклад %v (%v)",
+	"ics.description":       "%v\nТып: %v\nВыкладчык: %v\nПадгрупа: %v",
+	"ics.caption":           "Імпартуйце файл у каляндар тэлефона",
+
+	"subjects":       "*Прадметы*\n",
+	"subjects.none":  "`няма прадметаў`",
+	"groups":         "*Групы*\n%v\n`/group <ключ> выбірае групу для гэтага чата`",
+	"group.notfound": "група %v не знойдзена",
+	"group.selected": "Выбрана група %v",
+
+	"inline.error": "Памылка",
+	"inline.help":  "Запыт: today, tomorrow, week або nextweek і сцягі -l, -1, -2, -all, напрыклад today -2 -l",
+
+	"form.back":    "назад",
+	"form.skip":    "прапусціць",
+	"form.cancel":  "адмена",
+	"form.yes":     "так",
+	"form.no_skip": "Гэта поле нельга прапусціць",
+	"form.default": "Адкажыце «%v», каб выбраць %v",
+	"form.keep":    "Адкажыце «%v», каб пакінуць бягучае значэнне",
+
+	"wizard.week":             "Абярыце тыдзень занятку, 0 — кожны тыдзень",
+	"wizard.week.invalid":     "Такога варыянта няма, абярыце тыдзень занятку",
+	"wizard.subject":          "Абярыце прадмет",
+	"wizard.subject.invalid":  "Такога прадмета няма, абярыце прадмет са спісу",
+	"wizard.type":             "Абярыце тып занятку",
+	"wizard.type.invalid":     "Такога варыянта няма, абярыце тып занятку",
+	"wizard.day":              "Абярыце дзень занятку",
+	"wizard.day.invalid":      "Такога варыянта няма, абярыце дзень занятку",
+	"wizard.room":             "Увядзіце аўдыторыю занятку",
+	"wizard.room.empty":       "Аўдыторыя не можа быць пустой",
+	"wizard.period":           "Абярыце пару",
+	"wizard.period.invalid":   "Такога варыянта няма, абярыце пару",
+	"wizard.subgroup":         "Абярыце падгрупу, 0 — уся група",
+	"wizard.subgroup.invalid": "Такога варыянта няма, абярыце падгрупу",
+	"wizard.conflicts":        "⚠️ Занятак перасякаецца з:",
+	"wizard.conflict.overlap": "тая ж пара",
+	"wizard.conflict.room":    "аўдыторыя %v занятая",
+	"wizard.force":            "Усё роўна захаваць занятак? Адкажыце «так», каб захаваць, «назад», каб змяніць",
+	"wizard.force.invalid":    "Адкажыце «так», каб захаваць занятак, «назад», каб змяніць, або «адмена»",
+	"wizard.edit_id":          "Увядзіце ID занятку, які хочаце змяніць",
+	"wizard.delete_id":        "Увядзіце ID занятку, які хочаце выдаліць",
+	"wizard.invalid_id":       "Няправільны ID занятку",
+	"wizard.other_group":      "Занятак [ %v ] належыць іншай групе",
+	"wizard.delete":           "Выдаліць гэты занятак? Адкажыце «так», каб пацвердзіць",
+	"wizard.delete.invalid":   "Адкажыце «так», каб выдаліць занятак, або «адмена», каб пакінуць",
+	"wizard.add.cancelled":    "Даданне занятку адменена",
+	"wizard.edit.cancelled":   "Змяненне занятку адменена",
+	"wizard.delete.cancelled": "Выдаленне занятку адменена",
+	"wizard.added":            "Занятак дададзены",
+	"wizard.updated":          "Занятак [ %v ] зменены",
+	"wizard.deleted":          "Занятак [ %v ] выдалены",
+
+	"list.header":           "*Заняткі* %d-%d з %d",
+	"list.none":             "Заняткі не знойдзены",
+	"list.page":             "старонка %d з %d",
+	"list.every_week":       "кожны тыдзень",
+	"list.usage":            "/lectures [week=1-4] [day=1-6|пн] [subject=<ключ>] [sub=1|2]",
+	"list.invalid_filter":   "Няправільны фільтр %q, выкарыстанне: %v",
+	"list.unknown_filter":   "Невядомы фільтр %q, выкарыстанне: %v",
+	"list.invalid_week":     "Няправільны тыдзень %q, трэба ад 1 да %d",
+	"list.invalid_day":      "Няправільны дзень %q",
+	"list.invalid_subgroup": "Няправільная падгрупа %q, трэба 1 або 2",
+	"list.unknown_subject":  "Прадмет %q не знойдзены",
+	"list.denied":           "Гэта могуць рабіць толькі адміністратары групы",
+
+	"history.none":    "Змен няма",
+	"history.title":   "*Гісторыя*",
+	"history.entry":   "`%[1]v` %[2]v · карыстальнік %[3]v · %[5]v `%[6]v`: %[4]v",
+	"history.undo_of": " · адмена `%v`",
+	"history.undone":  " · адменена",
+	"history.footer":  "`/history <id>` — змены занятку, `/undo` адмяняе вашу апошнюю змену, `/undo <id>` — змену з гэтым id",
+	"undo.usage":      "выкарыстанне: /undo [id]",
+	"undo.none":       "Няма чаго адмяняць",
+	"undo.done":       "Адменена:",
+	"undo.denied":     "Адмяніць гэту змену можа толькі роля %v",
+	"audit.insert":    "даданне",
+	"audit.update":    "змяненне",
+	"audit.delete":    "выдаленне",
+	"audit.lecture":   "занятак",
+	"audit.subject":   "прадмет",
+	"audit.override":  "замена",
+	"audit.group":     "група",
+	"audit.grant":     "роля",
+	"audit.periods":   "пары",
+	"audit.days":      "дні",
+	"audit.types":     "тыпы",
+	"audit.lectures":  "заняткі",
+	"audit.unknown":   "невядома",
+
+	"grant.usage":       "выкарыстанне: /grant <userID> <owner|admin|editor> [група]",
+	"grant.done":        "Роля %v выдадзена %d%v",
+	"grant.list":        "*Ролі* групы %v",
+	"grant.everywhere":  " ва ўсіх групах",
+	"grant.group":       " у групе %v",
+	"revoke.usage":      "выкарыстанне: /revoke <userID> [група]",
+	"revoke.none":       "У карыстальніка %d няма роляў",
+	"revoke.last_owner": "Нельга адклікаць ролю апошняга ўладальніка",
+	"revoke.done":       "У %d адкліканы ролі: %v",
+
+	"subject.add_usage":    "выкарыстанне: /addsubject <ключ> | <назва> | <выкладчык>",
+	"subject.edit_usage":   "выкарыстанне: /editsubject <ключ> | <назва> | <выкладчык>\nпустое поле пакідае ранейшае значэнне",
+	"subject.name_usage":   "выкарыстанне: /subjectname <ключ> | <ru|en|be> | <назва>\nпустая назва выдаляе пераклад",
+	"subject.delete_usage": "выкарыстанне: /deletesubject <ключ>",
+	"subject.notfound":     "Прадмет [ %v ] не знойдзены",
+	"subject.added":        "Прадмет [ %v ] дададзены",
+	"subject.updated.one":  "Прадмет [ %[2]v ] зменены, у %[1]d занятку змяніўся выкладчык",
+	"subject.updated.few":  "Прадмет [ %[2]v ] зменены, у %[1]d заняткаў змяніўся выкладчык",
+	"subject.updated.many": "Прадмет [ %[2]v ] зменены, у %[1]d заняткаў змяніўся выкладчык",
+	"subject.renamed":      "Назва прадмета [ %[2]v ] на мове %[1]v зменена",
+	"subject.deleted":      "Прадмет [ %v ] выдалены",
+	"periods.usage":        "выкарыстанне: /setperiods <пачатак-канец> ...\nзараз: /setperiods %v",
+	"periods.invalid":      "Няправільная пара %q, укажыце пачатак і канец, напрыклад 8:00-9:40",
+	"periods.set.one":      "Зададзена %d пара",
+	"periods.set.few":      "Зададзены %d пары",
+	"periods.set.many":     "Зададзена %d пар",
+	"periods.in_use.one":   "%d занятак стаіць на парах, якія выдаляюцца, спачатку перанясіце або выдаліце яго:",
+	"periods.in_use.few":   "%d заняткі стаяць на парах, якія выдаляюцца, спачатку перанясіце або выдаліце іх:",
+	"periods.in_use.many":  "%d заняткаў стаяць на парах, якія выдаляюцца, спачатку перанясіце або выдаліце іх:",
+	"periods.more":         "... і яшчэ %d",
+	"group.usage":          "выкарыстанне: /addgroup <ключ> <назва>",
+	"group.added":          "Група [ %v ] дададзена",
+
+	"override.cancel_usage":     "выкарыстанне: /cancel <ID занятку> <ГГГГ-ММ-ДД>",
+	"override.change_usage":     "выкарыстанне: /%v <ID занятку> <ГГГГ-ММ-ДД> <значэнне>",
+	"override.oneoff_usage":     "выкарыстанне: /addoneoff <ГГГГ-ММ-ДД> <прадмет> <тып> <пара> <аўдыторыя> [падгрупа]",
+	"override.invalid_date":     "Няправільная дата %q, выкарыстоўвайце ГГГГ-ММ-ДД",
+	"override.not_on":           "Занятак [ %v ] праходзіць не ў гэты дзень тыдня (%v)",
+	"override.not_in_week":      "Занятак [ %v ] не праходзіць на тыдні %v",
+	"override.not_started":      "%v семестр яшчэ не пачаўся",
+	"override.unknown_subject":  "Невядомы прадмет %v, гл. /subjects",
+	"override.unknown_type":     "Невядомы тып %v",
+	"override.unknown_period":   "Невядомая пара %v",
+	"override.unknown_subgroup": "Невядомая падгрупа %v",
+	"override.saved.cancel":     "Адмена занятку [ %v ] на %v захавана",
+	"override.saved.change":     "Змена занятку [ %v ] на %v захавана",
+	"override.saved.extra":      "Разовы занятак на %v захаваны",
+	"override.notfound":         "Замена [ %v ] не знойдзена",
+	"override.deleted":          "Замена [ %v ] выдалена",
+	"overrides.none":            "замен няма",
+
+	"import.start":            "Дашліце файл .csv, .json або .yaml з заняткамі\nслупкі: %v",
+	"import.file":             "Дашліце файл .csv, .json або .yaml або адкажыце «адмена»",
+	"import.format":           "Невядомы фармат файла %q, выкарыстоўвайце .csv, .json або .yaml",
+	"import.cancelled":        "Імпарт заняткаў адменены",
+	"import.confirm":          "Адкажыце «confirm», каб імпартаваць, або «адмена», каб спыніць",
+	"import.force":            "Адкажыце «force», каб імпартаваць нягледзячы на перасячэнні, або «адмена», каб спыніць",
+	"import.done.one":         "Імпартаваны %d занятак",
+	"import.done.few":         "Імпартаваны %d заняткі",
+	"import.done.many":        "Імпартавана %d заняткаў",
+	"import.errors.one":       "Памылкі ў %d радку, нічога не імпартавана:",
+	"import.errors.few":       "Памылкі ў %d радках, нічога не імпартавана:",
+	"import.errors.many":      "Памылкі ў %d радках, нічога не імпартавана:",
+	"import.ready.one":        "%d занятак гатовы да імпарту:",
+	"import.ready.few":        "%d заняткі гатовыя да імпарту:",
+	"import.ready.many":       "%d заняткаў гатовыя да імпарту:",
+	"import.clashes.one":      "%d радок перасякаецца з іншымі заняткамі:",
+	"import.clashes.few":      "%d радкі перасякаюцца з іншымі заняткамі:",
+	"import.clashes.many":     "%d радкоў перасякаюцца з іншымі заняткамі:",
+	"import.more":             "... і яшчэ %d",
+	"import.row":              "радок %v: %v",
+	"import.new":              "новы",
+	"import.lecture":          "%v | тыдзень %v | дзень %v | пара %v | %v | %v | %v | падгрупа %v",
+	"import.clash":            "радок %v: перасякаецца з %v",
+	"import.clash_row":        "радок %v: перасякаецца з радком %v: %v",
+	"import.id_used":          "id %v ужо выкарыстаны ў радку %v",
+	"import.invalid_id":       "няправільны id %q",
+	"import.other_group":      "занятак адносіцца да групы %v, а не %v",
+	"import.other_group_id":   "занятак %v адносіцца да групы %v, а не %v",
+	"import.invalid_week":     "няправільны тыдзень %q, трэба ад 0 да 4",
+	"import.invalid_day":      "няправільны дзень %q",
+	"import.invalid_period":   "няправільная пара %q",
+	"import.unknown_subject":  "невядомы прадмет %q",
+	"import.invalid_type":     "няправільны тып %q",
+	"import.missing_room":     "не ўказана аўдыторыя",
+	"import.invalid_subgroup": "няправільная падгрупа %q, трэба ад 0 да 2",
+	"conflict.line":           "[ %v ] %v, тыдзень %v, дзень %v, пара %v, падгрупа %v, група %v: %v",
+	"conflict.pending":        "імпарт #%d",
+
+	"apitoken.private":      "Выкарыстоўвайце /apitoken у асабістым чаце з ботам",
+	"apitoken.revoked.one":  "Адкліканы %d API-токен",
+	"apitoken.revoked.few":  "Адкліканы %d API-токены",
+	"apitoken.revoked.many": "Адклікана %d API-токенаў",
+	"apitoken.issued":       "API-токен групы %v:\n%v\n\nперадавайце яго як Authorization: Bearer <token>, ранейшы токен больш не дзейнічае",
+
+	"help":         "*/today* `расклад на сёння`\n\n*/tomorrow* `расклад на заўтра`\n\n*/thisweek* `расклад на бягучы тыдзень`\n\n*/nextweek* `расклад на наступны тыдзень`\n\n*/week* `3 расклад бліжэйшага 3-га тыдня`\n\n*/weeks* `4 расклад на 4 тыдні наперад`\n\n*/whichweek* `які зараз тыдзень`\n\n*/now* `пара, якая ідзе зараз, і колькі да яе канца`\n\n*/next* `наступная пара, аўдыторыя і колькі да яе пачатку`\n\n*/date* `2026-11-03 або 03.11 расклад на дату, +1 праз тыдзень`\n\n*/day* `чацвер расклад на бліжэйшы чацвер, +1 на наступны`\n\n*/group* `спіс груп, /group <ключ> выбірае групу для гэтага чата`\n\n*/subjects* `спіс прадметаў групы`\n\n*/ics* `файл календара да канца семестра`\n\n*/calendar* `спасылка для падпіскі ў календары, /calendar revoke адклікае яе`\n\n*/remind* `on 15 -2 уключае напаміны за 15 хвілін да заняткаў, /remind off выключае іх`\n\n*/digest* `20:00 -2 -l дасылае расклад на заўтра кожны дзень у 20:00, /digest off выключае рассылку`\n\n*/settings* `падгрупа і рэжым па змаўчанні, мова і аўтавыдаленне паведамленняў`\n\n*/language* `ru, en або be мяняе мову чата`\n\n*/notify* `off выключае апавяшчэнні пра змены ў раскладзе, /notify on уключае іх`\n\n`@%v today -2` `адпраўляе расклад у любы чат, таксама tomorrow, week і nextweek`\n\n❌ `занятак адменены`  ⚠️ `змененыя аўдыторыя або выкладчык`  ➕ `дадатковы занятак`\n\n\n",
+	"help.flags":   "`Дадайце сцягі ў каманду, каб змяніць, што і як яна паказвае. сцягі:`\n*-l*  : `паказвае поўную назву прадмета і выкладчыка. па змаўчанні назва скарачаецца`\n\n*-s*  : `кароткія назвы прадметаў без выкладчыка, нават калі па змаўчанні выбраны поўны рэжым`\n\n*-1*  : `расклад падгрупы 1, па змаўчанні`\n\n*-2*  : `расклад падгрупы 2`\n\n*-all*  : `расклад усіх падгруп`\n\n",
+	"help.example": "*-Прыклад-*\n    /today -l -2\n`расклад на сёння для падгрупы 2 з выкладчыкамі і поўнымі назвамі прадметаў.`",
+}
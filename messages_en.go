@@ -0,0 +1,277 @@
+package main
+
+// english messages
+var messagesEn = map[string]string{
+	"day.0":       "Sunday",
+	"day.1":       "Monday",
+	"day.2":       "Tuesday",
+	"day.3":       "Wednesday",
+	"day.4":       "Thursday",
+	"day.5":       "Friday",
+	"day.6":       "Saturday",
+	"day.0.short": "sun",
+	"day.1.short": "mon",
+	"day.2.short": "tue",
+	"day.3.short": "wed",
+	"day.4.short": "thu",
+	"day.5.short": "fri",
+	"day.6.short": "sat",
+
+	"subgroup.all":         "all subgroups",
+	"subgroup.one":         "subgroup %v",
+	"week":                 "week %v",
+	"no_lectures":          "no classes 🎊",
+	"no_lectures.tomorrow": "no classes tomorrow 🎊",
+	"day_free":             "%v is free🎊",
+	"lectures.one":         "%d class",
+	"lectures.many":        "%d classes",
+	"minutes.one":          "%d minute",
+	"minutes.many":         "%d minutes",
+	"hours.one":            "%d hour",
+	"hours.many":           "%d hours",
+	"on":                   "on",
+	"off":                  "off",
+	"mode.long":            "detailed",
+	"mode.short":           "brief",
+
+	"now.current": "*Class in progress*, ends in %v, at %v",
+	"now.next":    "*Next class* in %v, at %v",
+	"now.break":   "Break after period %d, period %d starts in %v, at %v",
+	"now.none":    "No class right now",
+	"now.done":    "No more classes today 🎊",
+
+	"date.usage":     "/date 2026-11-03 or /date 03.11, +N moves the date N weeks ahead: /date 03.11 +1",
+	"date.day_usage": "/day thursday or /day thu, +N moves the day N weeks ahead: /day thu +1",
+
+	"weeks.which":      "This is week %d of %d, %v–%v\nnext is week %d",
+	"weeks.week_usage": "/week N shows the nearest week N, N from 1 to %d",
+	"weeks.usage":      "/weeks N shows the next N weeks, N from 1 to %d",
+
+	"nav.day":  "day",
+	"nav.week": "week",
+
+	"settings":            "*Settings*\nsubgroup: %v\nmode: %v\nlanguage: %v\ndelete messages: %v\n\n_the -l, -1, -2, -all flags of a command take precedence over the settings_",
+	"settings.autodelete": "delete messages: %v",
+	"settings.foreign":    "These are not your settings, send /settings",
+	"language.set":        "Language: %v",
+	"language.usage":      "Language: %v\n/language ru, en or be",
+
+	"notify.status.on":  "Timetable change notifications are on\n/notify off turns them off",
+	"notify.status.off": "Timetable change notifications are off\n/notify on turns them on",
+	"notify.on":         "Timetable change notifications are on",
+	"notify.off":        "Timetable change notifications are off",
+	"notify.changed":    "*Timetable change*",
+	"notify.deleted":    "*Class removed from the timetable*",
+	"notify.footer":     "_/notify off turns notifications off_",
+	"field.week":        "week",
+	"field.subject":     "subject",
+	"field.type":        "type",
+	"field.day":         "day",
+	"field.period":      "period",
+	"field.room":        "room",
+	"field.lecturer":    "lecturer",
+	"field.subgroup":    "subgroup",
+
+	"digest.status.off":  "The daily digest is off\n/digest 20:00 -2 -l sends tomorrow's timetable every day at 20:00",
+	"digest.status":      "Tomorrow's timetable arrives at %v, %v\n/digest off turns the digest off",
+	"digest.already_off": "The daily digest is already off",
+	"digest.off":         "The daily digest is off",
+	"digest.usage":       "/digest HH:MM [-1|-2|-all] [-l] or /digest off",
+	"digest.on":          "Tomorrow's timetable will arrive at %v, %v",
+
+	"remind":             "⏰ In %v: %v (%v)\nRoom: %v\nperiod %v, %v",
+	"remind.changed":     "⚠️ changed for today",
+	"remind.status.off":  "Reminders are off\n/remind on 15 -2 reminds 15 minutes before the classes of subgroup 2",
+	"remind.status":      "Reminders %v before classes, %v\n/remind off turns them off",
+	"remind.already_off": "Reminders are already off",
+	"remind.off":         "Reminders are off",
+	"remind.range":       "Give from 1 to %v minutes",
+	"remind.on":          "Reminders are on: %v before classes, %v",
+	"remind.usage":       "/remind on [minutes] [-1|-2|-all] or /remind off",
+
+	"calendar.private":      "Use /calendar in a private chat with the bot",
+	"calendar.none":         "You have no calendar link",
+	"calendar.revoked":      "The calendar link is revoked",
+	"calendar.unconfigured": "Calendar subscriptions are not set up, use /ics",
+	"calendar.link":         "Add the link to your calendar as a subscription:\n%v\n\nThe previous link no longer works. /calendar revoke revokes the link",
+	"ics.name":              "Timetable %v (%v)",
+	"ics.description":       "%v\nType: %v\nLecturer: %v\nSubgroup: %v",
+	"ics.caption":           "Import the file into your phone calendar",
+
+	"subjects":       "*Subjects*\n",
+	"subjects.none":  "`no subjects`",
+	"groups":         "*Groups*\n%v\n`/group <key> selects the group of this chat`",
+	"group.notfound": "group %v not found",
+	"group.selected": "Selected group %v",
+
+	"inline.error": "Error",
+	"inline.help":  "Query: today, tomorrow, week or nextweek and the -l, -1, -2, -all flags, e.g. today -2 -l",
+
+	"form.back":    "back",
+	"form.skip":    "skip",
+	"form.cancel":  "cancel",
+	"form.yes":     "yes",
+	"form.no_skip": "This field can't be skipped",
+	"form.default": "Reply %v to use %v",
+	"form.keep":    "Reply %v to keep the current value",
+
+	"wizard.week":             "Select the week of the lecture, 0 for every week",
+	"wizard.week.invalid":     "Invalid option, select the week of the lecture",
+	"wizard.subject":          "Choose the subject",
+	"wizard.subject.invalid":  "Invalid subject, choose one of the list",
+	"wizard.type":             "Select the type of the lecture",
+	"wizard.type.invalid":     "Invalid option, select the type of the lecture",
+	"wizard.day":              "Select the day of the lecture",
+	"wizard.day.invalid":      "Invalid option, select the day of the lecture",
+	"wizard.room":             "Enter the room of the lecture",
+	"wizard.room.empty":       "The room can't be empty",
+	"wizard.period":           "Select the period of the lecture",
+	"wizard.period.invalid":   "Invalid option, select the period of the lecture",
+	"wizard.subgroup":         "Select the subgroup of the lecture, 0 for the whole group",
+	"wizard.subgroup.invalid": "Invalid option, select the subgroup of the lecture",
+	"wizard.conflicts":        "⚠️ The lecture clashes with:",
+	"wizard.conflict.overlap": "same period",
+	"wizard.conflict.room":    "room %v taken",
+	"wizard.force":            "Save the lecture anyway? Reply yes to save it, back to change it",
+	"wizard.force.invalid":    "Reply yes to save the lecture, back to change it or cancel",
+	"wizard.edit_id":          "Enter the ID of the lecture you want to edit",
+	"wizard.delete_id":        "Enter the ID of the lecture you want to delete",
+	"wizard.invalid_id":       "Invalid lecture ID",
+	"wizard.other_group":      "Lecture [ %v ] belongs to another group",
+	"wizard.delete":           "Delete this lecture? Reply yes to confirm",
+	"wizard.delete.invalid":   "Reply yes to delete the lecture or cancel to keep it",
+	"wizard.add.cancelled":    "Lecture insert cancelled",
+	"wizard.edit.cancelled":   "Lecture update cancelled",
+	"wizard.delete.cancelled": "Lecture delete cancelled",
+	"wizard.added":            "Lecture added",
+	"wizard.updated":          "Lecture [ %v ] updated",
+	"wizard.deleted":          "Lecture [ %v ] deleted",
+
+	"list.header":           "*Lectures* %d-%d of %d",
+	"list.none":             "No lectures found",
+	"list.page":             "page %d of %d",
+	"list.every_week":       "every week",
+	"list.usage":            "/lectures [week=1-4] [day=1-6|monday] [subject=<key>] [sub=1|2]",
+	"list.invalid_filter":   "Invalid filter %q, usage: %v",
+	"list.unknown_filter":   "Unknown filter %q, usage: %v",
+	"list.invalid_week":     "Invalid week %q, must be 1 - %d",
+	"list.invalid_day":      "Invalid day %q",
+	"list.invalid_subgroup": "Invalid subgroup %q, must be 1 or 2",
+	"list.unknown_subject":  "Subject %q not found",
+	"list.denied":           "Only admins of the group can do this",
+
+	"history.none":    "No changes recorded",
+	"history.title":   "*History*",
+	"history.entry":   "`%v` %v · user %v · %v %v `%v`",
+	"history.undo_of": " · undo of `%v`",
+	"history.undone":  " · undone",
+	"history.footer":  "`/history <id>` shows the changes of a lecture, `/undo` reverts your latest change, `/undo <id>` the change with the id",
+	"undo.usage":      "usage: /undo [id]",
+	"undo.none":       "Nothing to undo",
+	"undo.done":       "Undone:",
+	"undo.denied":     "Only the %v role can undo this change",
+	"audit.insert":    "added",
+	"audit.update":    "changed",
+	"audit.delete":    "deleted",
+	"audit.lecture":   "lecture",
+	"audit.subject":   "subject",
+	"audit.override":  "override",
+	"audit.group":     "group",
+	"audit.grant":     "role",
+	"audit.periods":   "periods",
+	"audit.days":      "days",
+	"audit.types":     "types",
+	"audit.lectures":  "lectures",
+	"audit.unknown":   "unknown",
+
+	"grant.usage":       "usage: /grant <userID> <owner|admin|editor> [group]",
+	"grant.done":        "Granted %v to %d%v",
+	"grant.list":        "*Roles* of group %v",
+	"grant.everywhere":  " in every group",
+	"grant.group":       " in group %v",
+	"revoke.usage":      "usage: /revoke <userID> [group]",
+	"revoke.none":       "User %d has no role to revoke",
+	"revoke.last_owner": "Can't revoke the last owner",
+	"revoke.done":       "Revoked from %d: %v",
+
+	"subject.add_usage":    "usage: /addsubject <key> | <name> | <lecturer>",
+	"subject.edit_usage":   "usage: /editsubject <key> | <name> | <lecturer>\nleave a field empty to keep it",
+	"subject.name_usage":   "usage: /subjectname <key> | <ru|en|be> | <name>\nleave the name empty to remove the translation",
+	"subject.delete_usage": "usage: /deletesubject <key>",
+	"subject.notfound":     "Subject [ %v ] not found",
+	"subject.added":        "Added subject [ %v ] successfully",
+	"subject.updated.one":  "Updated subject [ %[2]v ] successfully, %[1]d lecture changed lecturer",
+	"subject.updated.many": "Updated subject [ %[2]v ] successfully, %[1]d lectures changed lecturer",
+	"subject.renamed":      "Updated the %v name of subject [ %v ] successfully",
+	"subject.deleted":      "Deleted subject [ %v ] successfully",
+	"periods.usage":        "usage: /setperiods <start-end> ...\ncurrent: /setperiods %v",
+	"periods.invalid":      "Invalid period %q, give its start and end like 8:00-9:40",
+	"periods.set.one":      "%d period set successfully",
+	"periods.set.many":     "%d periods set successfully",
+	"periods.in_use.one":   "%d lecture is in the periods being removed, move or delete it first:",
+	"periods.in_use.many":  "%d lectures are in the periods being removed, move or delete them first:",
+	"periods.more":         "... and %d more",
+	"group.usage":          "usage: /addgroup <key> <name>",
+	"group.added":          "Added group [ %v ] successfully",
+
+	"override.cancel_usage":     "usage: /cancel <lectureID> <YYYY-MM-DD>",
+	"override.change_usage":     "usage: /%v <lectureID> <YYYY-MM-DD> <value>",
+	"override.oneoff_usage":     "usage: /addoneoff <YYYY-MM-DD> <subject> <type> <period> <room> [subgroup]",
+	"override.invalid_date":     "Invalid date %q, use YYYY-MM-DD",
+	"override.not_on":           "Lecture [ %v ] is not on %v",
+	"override.not_in_week":      "Lecture [ %v ] is not in week %v",
+	"override.not_started":      "The semester has not started by %v",
+	"override.unknown_subject":  "Unknown subject %v, see /subjects",
+	"override.unknown_type":     "Unknown type %v",
+	"override.unknown_period":   "Unknown period %v",
+	"override.unknown_subgroup": "Unknown subgroup %v",
+	"override.saved.cancel":     "Cancel of lecture [ %v ] on %v saved",
+	"override.saved.change":     "Change of lecture [ %v ] on %v saved",
+	"override.saved.extra":      "One-off lecture on %v saved",
+	"override.notfound":         "Override [ %v ] not found",
+	"override.deleted":          "Deleted override [ %v ] successfully",
+	"overrides.none":            "no overrides",
+
+	"import.start":            "Send the .csv, .json or .yaml file with the lectures\ncolumns: %v",
+	"import.file":             "Send a .csv, .json or .yaml file, or cancel",
+	"import.format":           "Unknown file format %q, use .csv, .json or .yaml",
+	"import.cancelled":        "Lecture import cancelled",
+	"import.confirm":          "Reply confirm to import or cancel to stop",
+	"import.force":            "Reply force to import despite the clashes or cancel to stop",
+	"import.done.one":         "Imported %d lecture successfully",
+	"import.done.many":        "Imported %d lectures successfully",
+	"import.errors.one":       "%d row has errors, nothing was imported:",
+	"import.errors.many":      "%d rows have errors, nothing was imported:",
+	"import.ready.one":        "%d lecture ready to import:",
+	"import.ready.many":       "%d lectures ready to import:",
+	"import.clashes.one":      "%d row clashes with other lectures:",
+	"import.clashes.many":     "%d rows clash with other lectures:",
+	"import.more":             "... and %d more",
+	"import.row":              "row %v: %v",
+	"import.new":              "new",
+	"import.lecture":          "%v | week %v | day %v | period %v | %v | %v | %v | subgroup %v",
+	"import.clash":            "row %v: clashes with %v",
+	"import.clash_row":        "row %v: clashes with row %v: %v",
+	"import.id_used":          "id %v is already used in row %v",
+	"import.invalid_id":       "invalid id %q",
+	"import.other_group":      "lecture belongs to group %v, not %v",
+	"import.other_group_id":   "lecture %v belongs to group %v, not %v",
+	"import.invalid_week":     "invalid week %q, must be 0 - 4",
+	"import.invalid_day":      "invalid day %q",
+	"import.invalid_period":   "invalid period %q",
+	"import.unknown_subject":  "unknown subject %q",
+	"import.invalid_type":     "invalid type %q",
+	"import.missing_room":     "missing room",
+	"import.invalid_subgroup": "invalid subgroup %q, must be 0 - 2",
+	"conflict.line":           "[ %v ] %v, week %v, day %v, period %v, subgroup %v, group %v: %v",
+	"conflict.pending":        "import #%d",
+
+	"apitoken.private":      "Use /apitoken in a private chat with the bot",
+	"apitoken.revoked.one":  "%d api token revoked",
+	"apitoken.revoked.many": "%d api tokens revoked",
+	"apitoken.issued":       "API token for group %v:\n%v\n\nsend it as Authorization: Bearer <token>, the previous token no longer works",
+
+	"help":         "*/today* `today's timetable`\n\n*/tomorrow* `tomorrow's timetable`\n\n*/thisweek* `this week's timetable`\n\n*/nextweek* `next week's timetable`\n\n*/week* `3 the timetable of the nearest week 3`\n\n*/weeks* `4 the timetable of the next 4 weeks`\n\n*/whichweek* `which week it is`\n\n*/now* `the class in progress and how long it lasts`\n\n*/next* `the next class, its room and how soon it starts`\n\n*/date* `2026-11-03 or 03.11 the timetable of the date, +1 a week later`\n\n*/day* `thursday the timetable of the coming thursday, +1 the one after`\n\n*/group* `lists the groups, /group <key> selects the group of this chat`\n\n*/subjects* `the subjects of the group`\n\n*/ics* `a calendar file up to the end of the semester`\n\n*/calendar* `a link to subscribe to in a calendar app, /calendar revoke revokes it`\n\n*/remind* `on 15 -2 reminds 15 minutes before classes, /remind off turns reminders off`\n\n*/digest* `20:00 -2 -l sends tomorrow's timetable every day at 20:00, /digest off turns it off`\n\n*/settings* `default subgroup and mode, language and message deletion`\n\n*/language* `ru, en or be changes the language of the chat`\n\n*/notify* `off turns timetable change notifications off, /notify on turns them on`\n\n`@%v today -2` `sends the timetable into any chat, also tomorrow, week and nextweek`\n\n❌ `class cancelled`  ⚠️ `room or lecturer changed`  ➕ `extra class`\n\n\n",
+	"help.flags":   "`Add flags to a command to change what it shows. flags:`\n*-l*  : `shows the full subject name and the lecturer. subject names are abbreviated by default`\n\n*-s*  : `short names without the lecturer, even when long is your default`\n\n*-1*  : `the timetable of subgroup 1, the default`\n\n*-2*  : `the timetable of subgroup 2`\n\n*-all*  : `the timetable of all subgroups`\n\n",
+	"help.example": "*-Example-*\n    /today -l -2\n`today's timetable of subgroup 2 with lecturers and full subject names.`",
+}
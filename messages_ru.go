@@ -0,0 +1,288 @@
+package main
+
+// russian messages, the source catalog
+var messagesRu = map[string]string{
+	"day.0":       "Воскресенье",
+	"day.1":       "Понедельник",
+	"day.2":       "Вторник",
+	"day.3":       "Среда",
+	"day.4":       "Четверг",
+	"day.5":       "Пятница",
+	"day.6":       "Суббота",
+	"day.0.short": "вс",
+	"day.1.short": "пн",
+	"day.2.short": "вт",
+	"day.3.short": "ср",
+	"day.4.short": "чт",
+	"day.5.short": "пт",
+	"day.6.short": "сб",
+
+	"subgroup.all":         "все подгруппы",
+	"subgroup.one":         "подгруппа %v",
+	"week":                 "неделя %v",
+	"no_lectures":          "занятий нет 🎊",
+	"no_lectures.tomorrow": "завтра занятий нет 🎊",
+	"day_free":             "%v свободен🎊",
+	"lectures.one":         "%d пара",
+	"lectures.few":         "%d пары",
+	"lectures.many":        "%d пар",
+	"minutes.one":          "%d минуту",
+	"minutes.few":          "%d минуты",
+	"minutes.many":         "%d минут",
+	"hours.one":            "%d час",
+	"hours.few":            "%d часа",
+	"hours.many":           "%d часов",
+	"on":                   "вкл",
+	"off":                  "выкл",
+	"mode.long":            "подробно",
+	"mode.short":           "кратко",
+
+	"now.current": "*Сейчас идёт пара*, закончится через %v, в %v",
+	"now.next":    "*Следующая пара* через %v, в %v",
+	"now.break":   "Перемена после %d-й пары, %d-я пара начнётся через %v, в %v",
+	"now.none":    "Сейчас пар нет",
+	"now.done":    "Сегодня больше пар нет 🎊",
+
+	"date.usage":     "/date 2026-11-03 или /date 03.11, +N сдвигает дату на N недель: /date 03.11 +1",
+	"date.day_usage": "/day четверг или /day чт, +N сдвигает день на N недель: /day чт +1",
+
+	"weeks.which":      "Сейчас %d-я неделя из %d, %v–%v\nследующая — %d-я",
+	"weeks.week_usage": "/week N — расписание ближайшей N-й недели, N от 1 до %d",
+	"weeks.usage":      "/weeks N — расписание на N недель вперёд, N от 1 до %d",
+
+	"nav.day":  "день",
+	"nav.week": "неделя",
+
+	"settings":            "*Настройки*\nподгруппа: %v\nрежим: %v\nязык: %v\nавтоудаление сообщений: %v\n\n_флаги -l, -1, -2, -all в командах важнее настроек_",
+	"settings.autodelete": "автоудаление: %v",
+	"settings.foreign":    "Это не ваши настройки, отправьте /settings",
+	"language.set":        "Язык: %v",
+	"language.usage":      "Язык: %v\n/language ru, en или be",
+
+	"notify.status.on":  "Уведомления об изменениях в расписании включены\n/notify off выключает их",
+	"notify.status.off": "Уведомления об изменениях в расписании выключены\n/notify on включает их",
+	"notify.on":         "Уведомления об изменениях в расписании включены",
+	"notify.off":        "Уведомления об изменениях в расписании выключены",
+	"notify.changed":    "*Изменение в расписании*",
+	"notify.deleted":    "*Занятие удалено из расписания*",
+	"notify.footer":     "_/notify off выключает уведомления_",
+	"field.week":        "неделя",
+	"field.subject":     "предмет",
+	"field.type":        "тип",
+	"field.day":         "день",
+	"field.period":      "пара",
+	"field.room":        "аудитория",
+	"field.lecturer":    "преподаватель",
+	"field.subgroup":    "подгруппа",
+
+	"digest.status.off":  "Рассылка выключена\n/digest 20:00 -2 -l присылает расписание на завтра каждый день в 20:00",
+	"digest.status":      "Расписание на завтра приходит в %v, %v\n/digest off выключает рассылку",
+	"digest.already_off": "Рассылка уже выключена",
+	"digest.off":         "Рассылка выключена",
+	"digest.usage":       "/digest ЧЧ:ММ [-1|-2|-all] [-l] или /digest off",
+	"digest.on":          "Расписание на завтра будет приходить в %v, %v",
+
+	"remind":             "⏰ Через %v: %v (%v)\nАудитория: %v\n%v пара, %v",
+	"remind.changed":     "⚠️ изменения на сегодня",
+	"remind.status.off":  "Напоминания выключены\n/remind on 15 -2 включает напоминания за 15 минут до занятий подгруппы 2",
+	"remind.status":      "Напоминания за %v до занятий, %v\n/remind off выключает их",
+	"remind.already_off": "Напоминания уже выключены",
+	"remind.off":         "Напоминания выключены",
+	"remind.range":       "Укажите от 1 до %v минут",
+	"remind.on":          "Напоминания включены: за %v до занятий, %v",
+	"remind.usage":       "/remind on [минуты] [-1|-2|-all] или /remind off",
+
+	"calendar.private":      "Используйте /calendar в личном чате с ботом",
+	"calendar.none":         "У вас нет ссылки на календарь",
+	"calendar.revoked":      "Ссылка на календарь отозвана",
+	"calendar.unconfigured": "Подписка на календарь не настроена, используйте /ics",
+	"calendar.link":         "Добавьте ссылку в календарь как подписку:\n%v\n\nПредыдущая ссылка больше не работает. /calendar revoke отзывает ссылку",
+	"ics.name":              "Расписание %v (%v)",
+	"ics.description":       "%v\nТип: %v\nПреподаватель: %v\nПодгруппа: %v",
+	"ics.caption":           "Импортируйте файл в календарь телефона",
+
+	"subjects":       "*Предметы*\n",
+	"subjects.none":  "`нет предметов`",
+	"groups":         "*Группы*\n%v\n`/group <ключ> выбирает группу для этого чата`",
+	"group.notfound": "группа %v не найдена",
+	"group.selected": "Выбрана группа %v",
+
+	"inline.error": "Ошибка",
+	"inline.help":  "Запрос: today, tomorrow, week или nextweek и флаги -l, -1, -2, -all, например today -2 -l",
+
+	"form.back":    "назад",
+	"form.skip":    "пропустить",
+	"form.cancel":  "отмена",
+	"form.yes":     "да",
+	"form.no_skip": "Это поле нельзя пропустить",
+	"form.default": "Ответьте «%v», чтобы выбрать %v",
+	"form.keep":    "Ответьте «%v», чтобы оставить текущее значение",
+
+	"wizard.week":             "Выберите неделю занятия, 0 — каждая неделя",
+	"wizard.week.invalid":     "Такого варианта нет, выберите неделю занятия",
+	"wizard.subject":          "Выберите предмет",
+	"wizard.subject.invalid":  "Такого предмета нет, выберите предмет из списка",
+	"wizard.type":             "Выберите тип занятия",
+	"wizard.type.invalid":     "Такого варианта нет, выберите тип занятия",
+	"wizard.day":              "Выберите день занятия",
+	"wizard.day.invalid":      "Такого варианта нет, выберите день занятия",
+	"wizard.room":             "Введите аудиторию занятия",
+	"wizard.room.empty":       "Аудитория не может быть пустой",
+	"wizard.period":           "Выберите пару",
+	"wizard.period.invalid":   "Такого варианта нет, выберите пару",
+	"wizard.subgroup":         "Выберите подгруппу, 0 — вся группа",
+	"wizard.subgroup.invalid": "Такого варианта нет, выберите подгруппу",
+	"wizard.conflicts":        "⚠️ Занятие пересекается с:",
+	"wizard.conflict.overlap": "та же пара",
+	"wizard.conflict.room":    "аудитория %v занята",
+	"wizard.force":            "Всё равно сохранить занятие? Ответьте «да», чтобы сохранить, «назад», чтобы изменить",
+	"wizard.force.invalid":    "Ответьте «да», чтобы сохранить занятие, «назад», чтобы изменить, или «отмена»",
+	"wizard.edit_id":          "Введите ID занятия, которое хотите изменить",
+	"wizard.delete_id":        "Введите ID занятия, которое хотите удалить",
+	"wizard.invalid_id":       "Неверный ID занятия",
+	"wizard.other_group":      "Занятие [ %v ] принадлежит другой группе",
+	"wizard.delete":           "Удалить это занятие? Ответьте «да», чтобы подтвердить",
+	"wizard.delete.invalid":   "Ответьте «да», чтобы удалить занятие, или «отмена», чтобы оставить",
+	"wizard.add.cancelled":    "Добавление занятия отменено",
+	"wizard.edit.cancelled":   "Изменение занятия отменено",
+	"wizard.delete.cancelled": "Удаление занятия отменено",
+	"wizard.added":            "Занятие добавлено",
+	"wizard.updated":          "Занятие [ %v ] изменено",
+	"wizard.deleted":          "Занятие [ %v ] удалено",
+
+	"list.header":           "*Занятия* %d-%d из %d",
+	"list.none":             "Занятия не найдены",
+	"list.page":             "страница %d из %d",
+	"list.every_week":       "каждая неделя",
+	"list.usage":            "/lectures [week=1-4] [day=1-6|пн] [subject=<ключ>] [sub=1|2]",
+	"list.invalid_filter":   "Неверный фильтр %q, использование: %v",
+	"list.unknown_filter":   "Неизвестный фильтр %q, использование: %v",
+	"list.invalid_week":     "Неверная неделя %q, нужна от 1 до %d",
+	"list.invalid_day":      "Неверный день %q",
+	"list.invalid_subgroup": "Неверная подгруппа %q, нужна 1 или 2",
+	"list.unknown_subject":  "Предмет %q не найден",
+	"list.denied":           "Это могут делать только администраторы группы",
+
+	"history.none":    "Изменений нет",
+	"history.title":   "*История*",
+	"history.entry":   "`%[1]v` %[2]v · пользователь %[3]v · %[5]v `%[6]v`: %[4]v",
+	"history.undo_of": " · отмена `%v`",
+	"history.undone":  " · отменено",
+	"history.footer":  "`/history <id>` — изменения занятия, `/undo` отменяет ваше последнее изменение, `/undo <id>` — изменение с этим id",
+	"undo.usage":      "использование: /undo [id]",
+	"undo.none":       "Нечего отменять",
+	"undo.done":       "Отменено:",
+	"undo.denied":     "Отменить это изменение может только роль %v",
+	"audit.insert":    "добавление",
+	"audit.update":    "изменение",
+	"audit.delete":    "удаление",
+	"audit.lecture":   "занятие",
+	"audit.subject":   "предмет",
+	"audit.override":  "замена",
+	"audit.group":     "группа",
+	"audit.grant":     "роль",
+	"audit.periods":   "пары",
+	"audit.days":      "дни",
+	"audit.types":     "типы",
+	"audit.lectures":  "занятия",
+	"audit.unknown":   "неизвестно",
+
+	"grant.usage":       "использование: /grant <userID> <owner|admin|editor> [группа]",
+	"grant.done":        "Роль %v выдана %d%v",
+	"grant.list":        "*Роли* группы %v",
+	"grant.everywhere":  " во всех группах",
+	"grant.group":       " в группе %v",
+	"revoke.usage":      "использование: /revoke <userID> [группа]",
+	"revoke.none":       "У пользователя %d нет ролей",
+	"revoke.last_owner": "Нельзя отозвать роль последнего владельца",
+	"revoke.done":       "У %d отозваны роли: %v",
+
+	"subject.add_usage":    "использование: /addsubject <ключ> | <название> | <преподаватель>",
+	"subject.edit_usage":   "использование: /editsubject <ключ> | <название> | <преподаватель>\nпустое поле оставляет прежнее значение",
+	"subject.name_usage":   "использование: /subjectname <ключ> | <ru|en|be> | <название>\nпустое название удаляет перевод",
+	"subject.delete_usage": "использование: /deletesubject <ключ>",
+	"subject.notfound":     "Предмет [ %v ] не найден",
+	"subject.added":        "Предмет [ %v ] добавлен",
+	"subject.updated.one":  "Предмет [ %[2]v ] изменён, у %[1]d занятия сменился преподаватель",
+	"subject.updated.few":  "Предмет [ %[2]v ] изменён, у %[1]d занятий сменился преподаватель",
+	"subject.updated.many": "Предмет [ %[2]v ] изменён, у %[1]d занятий сменился преподаватель",
+	"subject.renamed":      "Название предмета [ %[2]v ] на языке %[1]v изменено",
+	"subject.deleted":      "Предмет [ %v ] удалён",
+	"periods.usage":        "использование: /setperiods <начало-конец> ...\nсейчас: /setperiods %v",
+	"periods.invalid":      "Неверная пара %q, укажите начало и конец, например 8:00-9:40",
+	"periods.set.one":      "Задана %d пара",
+	"periods.set.few":      "Заданы %d пары",
+	"periods.set.many":     "Задано %d пар",
+	"periods.in_use.one":   "%d занятие стоит на удаляемых парах, сначала перенесите или удалите его:",
+	"periods.in_use.few":   "%d занятия стоят на удаляемых парах, сначала перенесите или удалите их:",
+	"periods.in_use.many":  "%d занятий стоят на удаляемых парах, сначала перенесите или удалите их:",
+	"periods.more":         "... и ещё %d",
+	"group.usage":          "использование: /addgroup <ключ> <название>",
+	"group.added":          "Группа [ %v ] добавлена",
+
+	"override.cancel_usage":     "использование: /cancel <ID занятия> <ГГГГ-ММ-ДД>",
+	"override.change_usage":     "использование: /%v <ID занятия> <ГГГГ-ММ-ДД> <значение>",
+	"override.oneoff_usage":     "использование: /addoneoff <ГГГГ-ММ-ДД> <предмет> <тип> <пара> <аудитория> [подгруппа]",
+	"override.invalid_date":     "Неверная дата %q, используйте ГГГГ-ММ-ДД",
+	"override.not_on":           "Занятие [ %v ] проходит не в этот день недели (%v)",
+	"override.not_in_week":      "Занятие [ %v ] не проходит на неделе %v",
+	"override.not_started":      "%v семестр ещё не начался",
+	"override.unknown_subject":  "Неизвестный предмет %v, см. /subjects",
+	"override.unknown_type":     "Неизвестный тип %v",
+	"override.unknown_period":   "Неизвестная пара %v",
+	"override.unknown_subgroup": "Неизвестная подгруппа %v",
+	"override.saved.cancel":     "Отмена занятия [ %v ] на %v сохранена",
+	"override.saved.change":     "Изменение занятия [ %v ] на %v сохранено",
+	"override.saved.extra":      "Разовое занятие на %v сохранено",
+	"override.notfound":         "Замена [ %v ] не найдена",
+	"override.deleted":          "Замена [ %v ] удалена",
+	"overrides.none":            "замен нет",
+
+	"import.start":            "Отправьте файл .csv, .json или .yaml с занятиями\nстолбцы: %v",
+	"import.file":             "Отправьте файл .csv, .json или .yaml или ответьте «отмена»",
+	"import.format":           "Неизвестный формат файла %q, используйте .csv, .json или .yaml",
+	"import.cancelled":        "Импорт занятий отменён",
+	"import.confirm":          "Ответьте «confirm», чтобы импортировать, или «отмена», чтобы остановить",
+	"import.force":            "Ответьте «force», чтобы импортировать несмотря на пересечения, или «отмена», чтобы остановить",
+	"import.done.one":         "Импортировано %d занятие",
+	"import.done.few":         "Импортировано %d занятия",
+	"import.done.many":        "Импортировано %d занятий",
+	"import.errors.one":       "Ошибки в %d строке, ничего не импортировано:",
+	"import.errors.few":       "Ошибки в %d строках, ничего не импортировано:",
+	"import.errors.many":      "Ошибки в %d строках, ничего не импортировано:",
+	"import.ready.one":        "%d занятие готово к импорту:",
+	"import.ready.few":        "%d занятия готовы к импорту:",
+	"import.ready.many":       "%d занятий готовы к импорту:",
+	"import.clashes.one":      "%d строка пересекается с другими занятиями:",
+	"import.clashes.few":      "%d строки пересекаются с другими занятиями:",
+	"import.clashes.many":     "%d строк пересекаются с другими занятиями:",
+	"import.more":             "... и ещё %d",
+	"import.row":              "строка %v: %v",
+	"import.new":              "новое",
+	"import.lecture":          "%v | неделя %v | день %v | пара %v | %v | %v | %v | подгруппа %v",
+	"import.clash":            "строка %v: пересекается с %v",
+	"import.clash_row":        "строка %v: пересекается со строкой %v: %v",
+	"import.id_used":          "id %v уже использован в строке %v",
+	"import.invalid_id":       "неверный id %q",
+	"import.other_group":      "занятие относится к группе %v, а не %v",
+	"import.other_group_id":   "занятие %v относится к группе %v, а не %v",
+	"import.invalid_week":     "неверная неделя %q, нужна от 0 до 4",
+	"import.invalid_day":      "неверный день %q",
+	"import.invalid_period":   "неверная пара %q",
+	"import.unknown_subject":  "неизвестный предмет %q",
+	"import.invalid_type":     "неверный тип %q",
+	"import.missing_room":     "не указана аудитория",
+	"import.invalid_subgroup": "неверная подгруппа %q, нужна от 0 до 2",
+	"conflict.line":           "[ %v ] %v, неделя %v, день %v, пара %v, подгруппа %v, группа %v: %v",
+	"conflict.pending":        "импорт #%d",
+
+	"apitoken.private":      "Используйте /apitoken в личном чате с ботом",
+	"apitoken.revoked.one":  "Отозван %d API-токен",
+	"apitoken.revoked.few":  "Отозваны %d API-токена",
+	"apitoken.revoked.many": "Отозвано %d API-токенов",
+	"apitoken.issued":       "API-токен группы %v:\n%v\n\nпередавайте его как Authorization: Bearer <token>, прежний токен больше не действует",
+
+	"help":         "*/today* `команда возвращает расписание на сегодня`\n\n*/tomorrow* `команда возвращает расписание на завтра`\n\n*/thisweek* `команда возвращает расписание на текущую неделю`\n\n*/nextweek* `команда возвращает расписание на следующую неделю`\n\n*/week* `3 расписание ближайшей 3-й недели`\n\n*/weeks* `4 расписание на 4 недели вперёд`\n\n*/whichweek* `какая сейчас неделя`\n\n*/now* `пара, которая идёт сейчас, и сколько до её конца`\n\n*/next* `следующая пара, аудитория и сколько до её начала`\n\n*/date* `2026-11-03 или 03.11 расписание на дату, +1 через неделю`\n\n*/day* `четверг расписание на ближайший четверг, +1 на следующий`\n\n*/group* `показывает список групп, /group <ключ> выбирает группу для этого чата`\n\n*/subjects* `команда возвращает список предметов группы`\n\n*/ics* `команда возвращает файл календаря до конца семестра`\n\n*/calendar* `команда возвращает ссылку для подписки в календаре, /calendar revoke отзывает её`\n\n*/remind* `on 15 -2 включает напоминания за 15 минут до занятий, /remind off выключает их`\n\n*/digest* `20:00 -2 -l присылает расписание на завтра каждый день в 20:00, /digest off выключает рассылку`\n\n*/settings* `подгруппа и режим по умолчанию, язык и автоудаление сообщений`\n\n*/language* `ru, en или be меняет язык чата`\n\n*/notify* `off выключает уведомления об изменениях в расписании, /notify on включает их`\n\n`@%v today -2` `в любом чате отправляет расписание, также tomorrow, week и nextweek`\n\n❌ `занятие отменено`  ⚠️ `изменены аудитория или преподаватель`  ➕ `дополнительное занятие`\n\n\n",
+	"help.flags":   "`Добавьте флаги в команду, чтобы изменить, как и что возвращается. флаги:`\n*-l*  : `Отображает полное имя предмета и имя преподавателя. имя предмета по умолчанию сокращается`\n\n*-s*  : `краткие имена предметов без преподавателя, даже если по умолчанию выбран полный режим`\n\n*-1*  : `возвращает расписание для подгруппы 1 . по умолчанию` \n\n*-2*  : `возвращает расписание для подгруппы 2 `\n\n*-all*  : `возвращает расписание для всей подгруппы`\n\n",
+	"help.example": "*-Пример-*\n    /сегодня -l -2\n`Возвращает расписание на сегодня и для подгруппы 2 с именем лектора и полным именем предмета.`",
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Nav is the view of a schedule message, kept in its buttons' callback data
+// as "nav:<date>:<d|w>:<subgroup|a>:<s|l>"
+type Nav struct {
+	Date time.Time
+	Week bool
+	Opt  mdb.Args
+}
+
+func (n Nav) Data() string {
+	view, sub, mode := "d", n.Opt.Group, "s"
+	if n.Week {
+		view = "w"
+	}
+	if sub == "" {
+		sub = "a"
+	}
+	if n.Opt.Long {
+		mode = "l"
+	}
+	return fmt.Sprintf("nav:%v:%v:%v:%v", n.Date.Format(mdb.DateLayout), view, sub, mode)
+}
+
+func ParseNav(data string) (Nav, error) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 5 || parts[0] != "nav" {
+		return Nav{}, fmt.Errorf("invalid navigation data %q", data)
+	}
+	date, err := time.ParseInLocation(mdb.DateLayout, parts[1], time.Local)
+	if err != nil {
+		return Nav{}, err
+	}
+	nav := Nav{Date: date, Week: parts[2] == "w", Opt: mdb.Args{Group: parts[3], Long: parts[4] == "l"}}
+	if nav.Opt.Group == "a" {
+		nav.Opt.Group = ""
+	}
+	return nav, nil
+}
+
+// the subgroup after the current one in the toggle 1 → 2 → all
+func nextSubGroup(subGroup string) string {
+	switch subGroup {
+	case "1":
+		return "2"
+	case "2":
+		return ""
+	default:
+		return "1"
+	}
+}
+
+// the buttons of a schedule message, each leading to a neighbouring view
+func navKeyboard(n Nav) tgbotapi.InlineKeyboardMarkup {
+	lang := n.Opt.Lang
+	step, label := 1, T(lang, "nav.day")
+	if n.Week {
+		step, label = 7, T(lang, "nav.week")
+	}
+	prev, next, toggle, sub, mode := n, n, n, n, n
+	prev.Date = n.Date.AddDate(0, 0, -step)
+	next.Date = n.Date.AddDate(0, 0, step)
+	toggle.Week = !n.Week
+	toggleLabel := "📅 " + T(lang, "nav.week")
+	if n.Week {
+		toggleLabel = "📅 " + T(lang, "nav.day")
+	}
+	sub.Opt.Group = nextSubGroup(n.Opt.Group)
+	mode.Opt.Long = !n.Opt.Long
+	modeLabel := modeName(!n.Opt.Long, lang)
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀ "+label, prev.Data()),
+			tgbotapi.NewInlineKeyboardButtonData(toggleLabel, toggle.Data()),
+			tgbotapi.NewInlineKeyboardButtonData(label+" ▶", next.Data()),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👥 "+subGroupName(sub.Opt.Group, lang), sub.Data()),
+			tgbotapi.NewInlineKeyboardButtonData(modeLabel, mode.Data()),
+		),
+	)
+}
+
+// renders the day or the week of the view as one message
+func RenderNav(db mdb.Store, n Nav) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	cat, err := mdb.GetCatalog(db, n.Opt.StudyGroup)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	var days []DaySchedule
+	if n.Week {
+		n.Date = WeekStart(n.Date)
+		if days, err = GetWeekSchedule(db, n.Opt, n.Date); err != nil {
+			return "", tgbotapi.InlineKeyboardMarkup{}, err
+		}
+	} else {
+		day, err := GetDaySchedule(db, n.Opt, n.Date)
+		if err != nil {
+			return "", tgbotapi.InlineKeyboardMarkup{}, err
+		}
+		days = []DaySchedule{day}
+	}
+	var text string
+	if n.Week && len(days) > 0 {
+		last := days[len(days)-1].Date
+		text = fmt.Sprintf("_%v · %v–%v_\n", T(n.Opt.Lang, "week", weekLabel(days)), days[0].Date.Format("02.01"), last.Format("02.01"))
+	}
+	for _, day := range days {
+		title := fmt.Sprintf("%v, %v", dayName(cat, day.Day, n.Opt.Lang), day.Date.Format("02.01"))
+		if len(day.Lectures) == 0 {
+			text += fmt.Sprintf("*%v*\n%v\n", title, T(n.Opt.Lang, "no_lectures"))
+			continue
+		}
+		text += fmt.Sprintf("*%v* · %v\n", title, Tn(n.Opt.Lang, "lectures", len(day.Lectures)))
+		for _, lecture := range day.Lectures {
+			text += FormatLecture(lecture, n.Opt, cat)
+		}
+	}
+	return text + subGroupFooter(n.Opt), navKeyboard(n), nil
+}
+
+// sends the view as a new message with navigation buttons
+func SendNav(db mdb.Store, chatID int64, n Nav, bot *tgbotapi.BotAPI, mm *MessageManager) {
+	text, keyboard, err := RenderNav(db, n)
+	if err != nil {
+		SendMessage(bot, tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)), mm)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	SendMessage(bot, msg, mm)
+}
+
+// redraws the message the navigation button belongs to
+func HandleNavCallback(db mdb.Store, query *tgbotapi.CallbackQuery, group string, lang string, bot *tgbotapi.BotAPI) {
+	n, err := ParseNav(query.Data)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	n.Opt.StudyGroup = group
+	n.Opt.Lang = lang
+	text, keyboard, err := RenderNav(db, n)
+	if err != nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, keyboard)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := bot.Request(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("error editing message: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+func TestParseNav(t *testing.T) {
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		nav  Nav
+		data string
+	}{
+		{"day", Nav{Date: date, Opt: mdb.Args{Group: "1"}}, "nav:2026-10-19:d:1:s"},
+		{"week", Nav{Date: date, Week: true, Opt: mdb.Args{Group: "2", Long: true}}, "nav:2026-10-19:w:2:l"},
+		{"all subgroups", Nav{Date: date, Opt: mdb.Args{Group: ""}}, "nav:2026-10-19:d:a:s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if data := tt.nav.Data(); data != tt.data {
+				t.Errorf("Data() = %q, want %q", data, tt.data)
+			}
+			got, err := ParseNav(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Date.Equal(tt.nav.Date) || got.Week != tt.nav.Week || got.Opt != tt.nav.Opt {
+				t.Errorf("ParseNav(%q) = %+v, want %+v", tt.data, got, tt.nav)
+			}
+		})
+	}
+
+	for _, data := range []string{"", "nav", "nav:2026-10-19:d:1", "set:2026-10-19:d:1:s", "nav:19.10.2026:d:1:s"} {
+		if _, err := ParseNav(data); err == nil {
+			t.Errorf("ParseNav(%q) succeeded, want an error", data)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// adds the chat to the registry of chats told about timetable changes
+func RegisterChat(db mdb.ChatStore, chatID int64, group string) {
+	_, err := db.GetChat(chatID)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, mdb.ErrNotFound) {
+		log.Printf("error: %v", err)
+		return
+	}
+	if err := db.SaveChat(mdb.Chat{ID: chatID, Group: group}); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
+
+// remembers the subgroup the chat asked the timetable for, so it is only
+// notified about the lectures of that subgroup
+func RememberSubGroup(db mdb.ChatStore, chatID int64, subGroup string) {
+	chat, err := db.GetChat(chatID)
+	if err != nil || chat.SubGroup == subGroup {
+		return
+	}
+	chat.SubGroup = subGroup
+	if err := db.SaveChat(chat); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
+
+// handles "/notify on|off"
+func HandleNotify(db mdb.ChatStore, chatID int64, lang string, args string, bot *tgbotapi.BotAPI) {
+	chat, err := db.GetChat(chatID)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	switch strings.TrimSpace(args) {
+	case "on":
+		chat.Muted = false
+	case "off":
+		chat.Muted = true
+	default:
+		if chat.Muted {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "notify.status.off")))
+		} else {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "notify.status.on")))
+		}
+		return
+	}
+	if err := db.SaveChat(chat); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	if chat.Muted {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "notify.off")))
+	} else {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "notify.on")))
+	}
+}
+
+// tells the chats of the group about an edited lecture
+func NotifyLectureUpdate(db mdb.Store, before, after mdb.Lecture, bot *tgbotapi.BotAPI) {
+	cat, err := mdb.GetCatalog(db, before.Group)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	if LectureDiff(before, after, cat, defaultLanguage) == "" {
+		return
+	}
+	text := func(lang string) string {
+		return T(lang, "notify.changed") + "\n" + describeLecture(before, cat, lang) + "\n" + LectureDiff(before, after, cat, lang)
+	}
+	go broadcast(db, before.Group, []string{before.SubGroup, after.SubGroup}, text, bot)
+}
+
+// tells the chats of the group about a deleted lecture
+func NotifyLectureDelete(db mdb.Store, before mdb.Lecture, bot *tgbotapi.BotAPI) {
+	cat, err := mdb.GetCatalog(db, before.Group)
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	text := func(lang string) string {
+		return T(lang, "notify.deleted") + "\n" + describeLecture(before, cat, lang)
+	}
+	go broadcast(db, before.Group, []string{before.SubGroup}, text, bot)
+}
+
+// one line naming the lecture: subject, type, day, period and week
+func describeLecture(l mdb.Lecture, cat mdb.Catalog, lang string) string {
+	return fmt.Sprintf("`%v | %v | %v | %v | %v | %v`\n", subjectName(cat, l.Subject, lang), l.Type, dayName(cat, l.Day, lang),
+		cat.Periods[l.Time], T(lang, "week", l.Week), subGroupName(l.SubGroup, lang))
+}
+
+// lists the fields that differ between the two lectures as "before → after"
+func LectureDiff(before, after mdb.Lecture, cat mdb.Catalog, lang string) string {
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"field.week", before.Week, after.Week},
+		{"field.subject", subjectName(cat, before.Subject, lang), subjectName(cat, after.Subject, lang)},
+		{"field.type", before.Type, after.Type},
+		{"field.day", dayName(cat, before.Day, lang), dayName(cat, after.Day, lang)},
+		{"field.period", cat.Periods[before.Time].String(), cat.Periods[after.Time].String()},
+		{"field.room", before.Room, after.Room},
+		{"field.lecturer", before.Lecturer, after.Lecturer},
+		{"field.subgroup", subGroupName(before.SubGroup, lang), subGroupName(after.SubGroup, lang)},
+	}
+	var diff string
+	for _, f := range fields {
+		if f.before != f.after {
+			diff += fmt.Sprintf("%v: `%v` → `%v`\n", T(lang, f.name), f.before, f.after)
+		}
+	}
+	return diff
+}
+
+// reports whether a chat following subGroup sees lectures of one of subGroups
+func affects(subGroup string, subGroups []string) bool {
+	if subGroup == "" {
+		return true
+	}
+	for _, s := range subGroups {
+		if s == "0" || s == subGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// sends text in the language of the chat to every registered chat of the
+// group that follows one of the subgroups and has not opted out. chats that
+// blocked the bot are muted
+func broadcast(db mdb.Store, group string, subGroups []string, text func(lang string) string, bot *tgbotapi.BotAPI) {
+	chats, err := db.GetChats(bson.M{"group": group, "muted": bson.M{"$ne": true}})
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	sent := 0
+	for _, chat := range chats {
+		if !affects(chat.SubGroup, subGroups) {
+			continue
+		}
+		lang := chat.Language
+		if lang == "" {
+			lang = UserPrefs(db, chat.ID).Language
+		}
+		msg := tgbotapi.NewMessage(chat.ID, text(lang)+"\n"+T(lang, "notify.footer"))
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		_, err := bot.Send(msg)
+		var tgErr *tgbotapi.Error
+		if errors.As(err, &tgErr) && tgErr.Code == 403 {
+			chat.Muted = true
+			if err := db.SaveChat(chat); err != nil {
+				log.Printf("error: %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("sending error : %v", err)
+			continue
+		}
+		sent++
+	}
+	log.Printf("notified %d chats of group %v", sent, group)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sends what is going on right now: with next the upcoming lecture, else the
+// lecture in progress
+func SendNow(db mdb.Store, chatID int64, bot *tgbotapi.BotAPI, opt mdb.Args, next bool, mm *MessageManager) {
+	text, err := RenderNow(db, opt, time.Now(), next)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	SendMessage(bot, msg, mm)
+}
+
+// describes the moment at: the lecture in progress and how long it lasts, or
+// with next the upcoming lecture and how soon it starts. during a break both
+// name the break and when the next period begins
+func RenderNow(db mdb.Store, opt mdb.Args, at time.Time, next bool) (string, error) {
+	cat, err := mdb.GetCatalog(db, opt.StudyGroup)
+	if err != nil {
+		return "", err
+	}
+	schedule, err := GetDaySchedule(db, opt, at)
+	if err != nil {
+		return "", err
+	}
+	lang := opt.Lang
+	minute := at.Hour()*60 + at.Minute()
+	var current, upcoming []mdb.Lecture
+	currentEnd, upcomingStart := 0, -1
+	for _, lecture := range schedule.Lectures {
+		if lecture.Status == mdb.StatusCancelled {
+			continue
+		}
+		start, end, err := cat.Periods[lecture.Time].Minutes()
+		if err != nil {
+			continue
+		}
+		switch {
+		case start <= minute && minute < end:
+			current = append(current, lecture)
+			currentEnd = end
+		case start > minute && (upcomingStart < 0 || start < upcomingStart):
+			upcoming = []mdb.Lecture{lecture}
+			upcomingStart = start
+		case start == upcomingStart:
+			upcoming = append(upcoming, lecture)
+		}
+	}
+
+	// a break only matters when a lecture is still to come
+	var text string
+	if after, following, start, ok := currentBreak(cat.Periods, minute); ok && len(upcoming) > 0 {
+		text += T(lang, "now.break", after, following, formatDuration(start-minute, lang), formatClock(start)) + "\n"
+	}
+	if !next {
+		if len(current) > 0 {
+			text += T(lang, "now.current", formatDuration(currentEnd-minute, lang), formatClock(currentEnd)) + "\n"
+			for _, lecture := range current {
+				text += FormatLecture(lecture, opt, cat)
+			}
+			return text + subGroupFooter(opt), nil
+		}
+		if text == "" && len(upcoming) > 0 {
+			text += T(lang, "now.none") + "\n"
+		}
+	}
+	if len(upcoming) == 0 {
+		return text + T(lang, "now.done") + "\n" + subGroupFooter(opt), nil
+	}
+	text += T(lang, "now.next", formatDuration(upcomingStart-minute, lang), formatClock(upcomingStart)) + "\n"
+	for _, lecture := range upcoming {
+		text += FormatLecture(lecture, opt, cat)
+	}
+	return text + subGroupFooter(opt), nil
+}
+
+// finds the break minute falls into: the number of the period it follows,
+// the number of the period after it and when that one starts
+func currentBreak(periods map[int]mdb.Period, minute int) (after int, next int, start int, ok bool) {
+	numbers := slices.Sorted(maps.Keys(periods))
+	for i := 0; i+1 < len(numbers); i++ {
+		_, end, err1 := periods[numbers[i]].Minutes()
+		start, _, err2 := periods[numbers[i+1]].Minutes()
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if end <= minute && minute < start {
+			return numbers[i], numbers[i+1], start, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// formats minutes since midnight as "8:05"
+func formatClock(minute int) string {
+	return fmt.Sprintf("%d:%02d", minute/60, minute%60)
+}
+
+// formats a duration in minutes as "1 hour 20 minutes"
+func formatDuration(minutes int, lang string) string {
+	if minutes < 60 {
+		return Tn(lang, "minutes", minutes)
+	}
+	text := Tn(lang, "hours", minutes/60)
+	if minutes%60 != 0 {
+		text += " " + Tn(lang, "minutes", minutes%60)
+	}
+	return text
+}
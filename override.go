@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// returns the midnight the date starts with
+func DayStart(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+}
+
+// returns the monday of the week the date is in
+func WeekStart(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return DayStart(date.AddDate(0, 0, -offset))
+}
+
+// loads the overrides of the group for the dates, keyed by date
+func GetOverridesOn(db mdb.OverrideStore, group string, dates ...time.Time) (map[string][]mdb.Override, error) {
+	var keys []string
+	for _, date := range dates {
+		keys = append(keys, date.Format(mdb.DateLayout))
+	}
+	overrides, err := db.GetOverrides(bson.M{"group": group, "date": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string][]mdb.Override)
+	for _, o := range overrides {
+		byDate[o.Date] = append(byDate[o.Date], o)
+	}
+	return byDate, nil
+}
+
+func parseDate(str string) (time.Time, error) {
+	date, err := time.ParseInLocation(mdb.DateLayout, str, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", str)
+	}
+	return date, nil
+}
+
+// finds the lecture of the group and checks it takes place on the date
+func lectureOn(db mdb.LectureStore, group string, lectureID string, date time.Time, lang string) (mdb.Lecture, error) {
+	lecture, err := db.GetLecture(lectureID)
+	if err != nil {
+		return mdb.Lecture{}, err
+	}
+	if lecture.Group != group {
+		return mdb.Lecture{}, errors.New(T(lang, "wizard.other_group", lectureID))
+	}
+	if lecture.Day != int(date.Weekday()) {
+		return mdb.Lecture{}, errors.New(T(lang, "override.not_on", lectureID, T(lang, fmt.Sprintf("day.%d", date.Weekday()))))
+	}
+	week, err := GetWeekAt(os.Getenv("SEMESTER_START_DATE"), date)
+	if errors.Is(err, errNotStarted) {
+		return mdb.Lecture{}, errors.New(T(lang, "override.not_started", date.Format(mdb.DateLayout)))
+	}
+	if err != nil {
+		return mdb.Lecture{}, err
+	}
+	if lecture.Week != "0" && lecture.Week != fmt.Sprint(week) {
+		return mdb.Lecture{}, errors.New(T(lang, "override.not_in_week", lectureID, week))
+	}
+	return lecture, nil
+}
+
+// cancels a lecture on a date from "/cancel <id> <date>"
+func CancelLecture(db mdb.Store, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.cancel_usage")))
+		return
+	}
+	insertOverride(db, chatID, group, fields[0], fields[1], mdb.Override{Kind: mdb.OverrideCancel}, lang, bot)
+}
+
+// changes the room or the lecturer of a lecture on a date from
+// "/changeroom <id> <date> <room>" or "/changelecturer <id> <date> <lecturer>"
+func ChangeLecture(db mdb.Store, chatID int64, group string, command string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 3)
+	if len(fields) != 3 || strings.TrimSpace(fields[2]) == "" {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.change_usage", command)))
+		return
+	}
+	o := mdb.Override{Kind: mdb.OverrideChange}
+	if command == "changeroom" {
+		o.Room = strings.TrimSpace(fields[2])
+	} else {
+		o.Lecturer = strings.TrimSpace(fields[2])
+	}
+	insertOverride(db, chatID, group, fields[0], fields[1], o, lang, bot)
+}
+
+func insertOverride(db mdb.Store, chatID int64, group string, lectureID string, dateStr string, o mdb.Override, lang string, bot *tgbotapi.BotAPI) {
+	date, err := parseDate(dateStr)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.invalid_date", dateStr)))
+		return
+	}
+	lecture, err := lectureOn(db, group, strings.ToLower(lectureID), date, lang)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	o.Group = group
+	o.Date = date.Format(mdb.DateLayout)
+	o.LectureID = lecture.ID
+	if err := db.InsertOverride(o); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	log.Printf("New override : %+v", o)
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.saved."+string(o.Kind), lecture.ID.Hex(), o.Date)))
+}
+
+// adds a lecture for a single date from
+// "/addoneoff <date> <subject> <type> <period> <room> [subgroup]"
+func AddOneOff(db mdb.Store, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	if len(fields) != 5 && len(fields) != 6 {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.oneoff_usage")))
+		return
+	}
+	date, err := parseDate(fields[0])
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.invalid_date", fields[0])))
+		return
+	}
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	subject, ok := cat.Subjects[fields[1]]
+	if !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.unknown_subject", fields[1])))
+		return
+	}
+	if _, ok := cat.Types[fields[2]]; !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.unknown_type", fields[2])))
+		return
+	}
+	period, err := strconv.Atoi(fields[3])
+	if _, ok := cat.Periods[period]; err != nil || !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.unknown_period", fields[3])))
+		return
+	}
+	subGroup := "0"
+	if len(fields) == 6 {
+		subGroup = fields[5]
+	}
+	if _, ok := mdb.SubGroup[subGroup]; !ok {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.unknown_subgroup", subGroup)))
+		return
+	}
+	o := mdb.Override{
+		Group: group,
+		Date:  date.Format(mdb.DateLayout),
+		Kind:  mdb.OverrideExtra,
+		Lecture: mdb.Lecture{
+			Week:     "0",
+			Subject:  subject.Key,
+			Lecturer: subject.Lecturer,
+			Type:     fields[2],
+			Time:     period,
+			Day:      int(date.Weekday()),
+			Room:     fields[4],
+			SubGroup: subGroup,
+			Group:    group,
+		},
+	}
+	if err := db.InsertOverride(o); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	log.Printf("New override : %+v", o)
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.saved.extra", o.Date)))
+}
+
+// lists the overrides of the group from the date on, today by default
+func SendOverrides(db mdb.OverrideStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	from := time.Now()
+	if str := strings.TrimSpace(args); str != "" {
+		date, err := parseDate(str)
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.invalid_date", str)))
+			return
+		}
+		from = date
+	}
+	overrides, err := db.GetOverrides(bson.M{"group": group})
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	var content string
+	for _, o := range overrides {
+		if o.Date < from.Format(mdb.DateLayout) {
+			continue
+		}
+		content += fmt.Sprintf("`%v` %v %v", o.ID.Hex(), o.Date, o.Kind)
+		switch o.Kind {
+		case mdb.OverrideCancel:
+			content += fmt.Sprintf(" `%v`", o.LectureID.Hex())
+		case mdb.OverrideChange:
+			content += fmt.Sprintf(" `%v` %v%v", o.LectureID.Hex(), o.Room, o.Lecturer)
+		case mdb.OverrideExtra:
+			content += fmt.Sprintf(" %v %v %v %v", o.Lecture.Subject, o.Lecture.Type, o.Lecture.Time, o.Lecture.Room)
+		}
+		content += "\n"
+	}
+	if content == "" {
+		content = T(lang, "overrides.none")
+	}
+	msg := tgbotapi.NewMessage(chatID, content)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	bot.Send(msg)
+}
+
+// removes an override from "/deleteoverride <id>"
+func DeleteOverride(db mdb.OverrideStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	id := strings.ToLower(strings.TrimSpace(args))
+	overrides, err := db.GetOverrides(bson.M{"group": group})
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	found := false
+	for _, o := range overrides {
+		if o.ID.Hex() == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.notfound", id)))
+		return
+	}
+	if err := db.DeleteOverride(id); err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, T(lang, "override.deleted", id)))
+}
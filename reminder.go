@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultReminderMinutes = 15
+	maxReminderMinutes     = 180
+)
+
+// sends the reminders that fall between from and to
+func (s *Scheduler) remind(from, to time.Time) {
+	subscriptions, err := s.db.GetSubscriptions(bson.M{"kind": mdb.SubscriptionReminder})
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	type key struct{ group, subGroup string }
+	schedules := make(map[key]DaySchedule)
+	catalogs := make(map[string]mdb.Catalog)
+	for _, sub := range subscriptions {
+		k := key{sub.Group, sub.SubGroup}
+		schedule, ok := schedules[k]
+		if !ok {
+			if schedule, err = GetDaySchedule(s.db, mdb.Args{StudyGroup: sub.Group, Group: sub.SubGroup}, to); err != nil {
+				log.Printf("error: %v", err)
+				continue
+			}
+			schedules[k] = schedule
+		}
+		cat, ok := catalogs[sub.Group]
+		if !ok {
+			if cat, err = mdb.GetCatalog(s.db, sub.Group); err != nil {
+				log.Printf("error: %v", err)
+				continue
+			}
+			catalogs[sub.Group] = cat
+		}
+		for _, lecture := range schedule.Lectures {
+			if lecture.Status == mdb.StatusCancelled {
+				continue
+			}
+			start, _, err := cat.Periods[lecture.Time].Minutes()
+			if err != nil {
+				continue
+			}
+			y, m, d := schedule.Date.Date()
+			at := time.Date(y, m, d, 0, start-sub.Minutes, 0, 0, schedule.Date.Location())
+			if at.After(from) && !at.After(to) {
+				lang := Language(s.db, sub.ChatID, sub.ChatID)
+				s.send(sub, tgbotapi.NewMessage(sub.ChatID, FormatReminder(lecture, sub.Minutes, cat, lang)))
+			}
+		}
+	}
+}
+
+func FormatReminder(lecture mdb.Lecture, minutes int, cat mdb.Catalog, lang string) string {
+	text := T(lang, "remind", Tn(lang, "minutes", minutes), subjectName(cat, lecture.Subject, lang), lecture.Type, lecture.Room, lecture.Time, cat.Periods[lecture.Time])
+	if lecture.Status == mdb.StatusChanged {
+		text += "\n" + T(lang, "remind.changed")
+	}
+	return text
+}
+
+// reads the "[minutes] [-1|-2|-all]" of "/remind on". the subgroup flags
+// start with "-", so they are never taken for the minutes
+func parseRemindOn(fields []string) (int, mdb.Args) {
+	minutes := defaultReminderMinutes
+	if len(fields) > 0 && !strings.HasPrefix(fields[0], "-") {
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			minutes = n
+		}
+	}
+	return minutes, ParseArgs(strings.Join(fields, " "))
+}
+
+// handles "/remind on [minutes] [-1|-2|-all]", "/remind off" and "/remind"
+func HandleRemind(db mdb.SubscriptionStore, chatID int64, group string, lang string, args string, bot *tgbotapi.BotAPI) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		subs, err := db.GetSubscriptions(bson.M{"_id": mdb.SubscriptionID(chatID, mdb.SubscriptionReminder)})
+		if err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		if len(subs) == 0 {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.status.off")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.status", Tn(lang, "minutes", subs[0].Minutes), subGroupName(subs[0].SubGroup, lang))))
+		return
+	}
+	switch fields[0] {
+	case "off":
+		if err := db.DeleteSubscription(chatID, mdb.SubscriptionReminder); err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.already_off")))
+			return
+		}
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.off")))
+	case "on":
+		minutes, arg := parseRemindOn(fields[1:])
+		if minutes < 1 || minutes > maxReminderMinutes {
+			bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.range", maxReminderMinutes)))
+			return
+		}
+		sub := mdb.Subscription{
+			ChatID:   chatID,
+			Kind:     mdb.SubscriptionReminder,
+			Group:    group,
+			SubGroup: arg.Group,
+			Minutes:  minutes,
+		}
+		if err := db.SaveSubscription(sub); err != nil {
+			bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+			return
+		}
+		log.Printf("chat %v subscribed to reminders: %+v", chatID, sub)
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.on", Tn(lang, "minutes", minutes), subGroupName(arg.Group, lang))))
+	default:
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "remind.usage")))
+	}
+}
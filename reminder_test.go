@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRemindOn(t *testing.T) {
+	tests := []struct {
+		args     string
+		minutes  int
+		subGroup string
+	}{
+		{"", defaultReminderMinutes, "1"},
+		{"30", 30, "1"},
+		{"30 -2", 30, "2"},
+		{"-2", defaultReminderMinutes, "2"},
+		{"-1", defaultReminderMinutes, "1"},
+		{"-all", defaultReminderMinutes, ""},
+		{"-all 30", defaultReminderMinutes, ""},
+		{"soon", defaultReminderMinutes, "1"},
+		{"0", 0, "1"},
+	}
+	for _, tt := range tests {
+		minutes, arg := parseRemindOn(strings.Fields(tt.args))
+		if minutes != tt.minutes || arg.Group != tt.subGroup {
+			t.Errorf("parseRemindOn(%q) = %d minutes, subgroup %q, want %d, %q", tt.args, minutes, arg.Group, tt.minutes, tt.subGroup)
+		}
+	}
+}
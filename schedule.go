@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+// DaySchedule is the timetable of one date with its overrides applied
+type DaySchedule struct {
+	Date     time.Time
+	Week     int
+	Day      int
+	Lectures []mdb.Lecture
+}
+
+// loads the timetable of the date for the group and subgroup in opt
+func GetDaySchedule(db mdb.Store, opt mdb.Args, date time.Time) (DaySchedule, error) {
+	week, err := GetWeekAt(os.Getenv("SEMESTER_START_DATE"), date)
+	if err != nil {
+		return DaySchedule{}, err
+	}
+	day := int(date.Weekday())
+	var lectures []mdb.Lecture
+	// sunday has no recurring lectures and a zero day would match every day
+	if day != int(time.Sunday) {
+		query := mdb.LectureQuery{Group: opt.StudyGroup, Week: week, Day: day, SubGroup: opt.Group}
+		if lectures, err = db.GetLectures(query.Filter()); err != nil {
+			return DaySchedule{}, err
+		}
+	}
+	overrides, err := GetOverridesOn(db, opt.StudyGroup, date)
+	if err != nil {
+		return DaySchedule{}, err
+	}
+	return DaySchedule{
+		Date:     DayStart(date),
+		Week:     week,
+		Day:      day,
+		Lectures: mdb.ApplyOverrides(lectures, overrides[date.Format(mdb.DateLayout)], opt.Group),
+	}, nil
+}
+
+// loads the timetable from monday to saturday of the week starting on
+// monday. academic weeks begin on the weekday the semester started on, so
+// each day gets its own week. days before the semester have no lectures
+func GetWeekSchedule(db mdb.Store, opt mdb.Args, monday time.Time) ([]DaySchedule, error) {
+	query := mdb.LectureQuery{Group: opt.StudyGroup, SubGroup: opt.Group}
+	lectures, err := db.GetLectures(query.Filter())
+	if err != nil {
+		return nil, err
+	}
+	var dates []time.Time
+	for day := 1; day <= 6; day++ {
+		dates = append(dates, DayStart(monday).AddDate(0, 0, day-1))
+	}
+	overrides, err := GetOverridesOn(db, opt.StudyGroup, dates...)
+	if err != nil {
+		return nil, err
+	}
+	days := make([]DaySchedule, len(dates))
+	for i, date := range dates {
+		week, err := GetWeekAt(os.Getenv("SEMESTER_START_DATE"), date)
+		if err != nil && !errors.Is(err, errNotStarted) {
+			return nil, err
+		}
+		var day []mdb.Lecture
+		for _, lecture := range lectures {
+			if week != 0 && lecture.Day == i+1 && (lecture.Week == "0" || lecture.Week == strconv.Itoa(week)) {
+				day = append(day, lecture)
+			}
+		}
+		days[i] = DaySchedule{
+			Date:     date,
+			Week:     week,
+			Day:      i + 1,
+			Lectures: mdb.ApplyOverrides(day, overrides[date.Format(mdb.DateLayout)], opt.Group),
+		}
+	}
+	return days, nil
+}
+
+// the academic weeks the days fall in, "2" or "2/3" when a week begins
+// midway
+func weekLabel(days []DaySchedule) string {
+	var weeks []string
+	for _, day := range days {
+		week := strconv.Itoa(day.Week)
+		if day.Week != 0 && !slices.Contains(weeks, week) {
+			weeks = append(weeks, week)
+		}
+	}
+	return strings.Join(weeks, "/")
+}
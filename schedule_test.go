@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+func TestGetWeekSchedule(t *testing.T) {
+	t.Setenv("SEMESTER_START_DATE", "2026-09-01")
+	db := mdb.NewMemDb()
+	if err := prepareStore(db, "g"); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []mdb.Lecture{
+		{Week: "0", Day: 1, Time: 1, Subject: "ОМО", Type: "ЛК", Room: "101", SubGroup: "0", Group: "g"},
+		{Week: "1", Day: 1, Time: 2, Subject: "ТЭ", Type: "ЛР", Room: "102", SubGroup: "0", Group: "g"},
+		{Week: "2", Day: 2, Time: 2, Subject: "ФК", Type: "ПЗ", Room: "103", SubGroup: "0", Group: "g"},
+	} {
+		if err := db.InsertLecture(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		monday string
+		label  string
+		// the lectures of monday and tuesday
+		mon, tue int
+	}{
+		// monday is before the semester
+		{"2026-08-31", "1", 0, 0},
+		// monday ends week 1, tuesday starts week 2
+		{"2026-09-07", "1/2", 2, 1},
+		{"2026-09-14", "2/3", 1, 0},
+	}
+	for _, tt := range tests {
+		monday, err := time.ParseInLocation("2006-01-02", tt.monday, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		days, err := GetWeekSchedule(db, mdb.Args{StudyGroup: "g"}, monday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if label := weekLabel(days); label != tt.label || len(days[0].Lectures) != tt.mon || len(days[1].Lectures) != tt.tue {
+			t.Errorf("week of %v: weeks %q, %d and %d lectures, want %q, %d and %d", tt.monday, label, len(days[0].Lectures), len(days[1].Lectures), tt.label, tt.mon, tt.tue)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// how often the scheduler wakes up
+const schedulerTick = 30 * time.Second
+
+// Job runs on every tick of the scheduler with the time passed since the
+// previous tick, from exclusive and to inclusive
+type Job func(from, to time.Time)
+
+// Scheduler runs jobs that message subscribed chats at given times
+type Scheduler struct {
+	db   mdb.Store
+	bot  *tgbotapi.BotAPI
+	jobs []Job
+}
+
+func NewScheduler(db mdb.Store, bot *tgbotapi.BotAPI) *Scheduler {
+	return &Scheduler{db: db, bot: bot}
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// runs the jobs in the background until the process exits
+func (s *Scheduler) Start() {
+	go func() {
+		last := time.Now()
+		ticker := time.NewTicker(schedulerTick)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			for _, job := range s.jobs {
+				job(last, now)
+			}
+			last = now
+		}
+	}()
+}
+
+// sends a message to a subscribed chat, dropping the subscription when the
+// bot was blocked or removed from the chat
+func (s *Scheduler) send(sub mdb.Subscription, msg tgbotapi.Chattable) {
+	_, err := s.bot.Send(msg)
+	if err == nil {
+		return
+	}
+	log.Printf("sending error : %v", err)
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.Code == 403 {
+		if err := s.db.DeleteSubscription(sub.ChatID, sub.Kind); err != nil {
+			log.Printf("error: %v", err)
+		}
+		log.Printf("dropped %v subscription of chat %v", sub.Kind, sub.ChatID)
+	}
+}
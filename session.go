@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+)
+
+// how long a wizard may sit idle before it is dropped
+const defaultSessionTimeout = 30 * time.Minute
+
+// Sessions hands out the wizard sessions of the store, treating those idle
+// for longer than the timeout as gone
+type Sessions struct {
+	db      mdb.SessionStore
+	timeout time.Duration
+}
+
+// the idle timeout is read from TIMETABLE_SESSION_TIMEOUT, e.g. "1h"
+func NewSessions(db mdb.SessionStore) *Sessions {
+	timeout := defaultSessionTimeout
+	if env := os.Getenv("TIMETABLE_SESSION_TIMEOUT"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil || d <= 0 {
+			log.Printf("error: invalid TIMETABLE_SESSION_TIMEOUT %q, using %v", env, timeout)
+		} else {
+			timeout = d
+		}
+	}
+	return &Sessions{db: db, timeout: timeout}
+}
+
+// returns the live session of the user in the chat, if any
+func (s *Sessions) Get(chatID, userID int64) (mdb.Session, bool) {
+	session, err := s.db.GetSession(chatID, userID)
+	if err != nil {
+		if !errors.Is(err, mdb.ErrNotFound) {
+			log.Printf("error: %v", err)
+		}
+		return mdb.Session{}, false
+	}
+	if time.Since(session.Updated) > s.timeout {
+		return mdb.Session{}, false
+	}
+	return session, true
+}
+
+// stores the session and restarts its idle timeout. starting a wizard
+// replaces any other session of the user in the chat
+func (s *Sessions) Save(session mdb.Session) {
+	session.Updated = time.Now()
+	if err := s.db.SaveSession(session); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
+
+func (s *Sessions) Delete(chatID, userID int64) {
+	if err := s.db.DeleteSession(chatID, userID); err != nil {
+		log.Printf("error: %v", err)
+	}
+}
+
+// scheduler job dropping abandoned sessions
+func (s *Sessions) cleanup(from, to time.Time) {
+	count, err := s.db.DeleteSessionsBefore(to.Add(-s.timeout))
+	if err != nil {
+		log.Printf("error: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("dropped %d idle sessions", count)
+	}
+}
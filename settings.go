@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// returns the saved preferences of the user, or the defaults
+func UserPrefs(db mdb.PrefsStore, userID int64) mdb.Prefs {
+	p, err := db.GetPrefs(userID)
+	if err != nil {
+		if !errors.Is(err, mdb.ErrNotFound) {
+			log.Printf("error: %v", err)
+		}
+		return mdb.DefaultPrefs(userID)
+	}
+	return p
+}
+
+// the message manager for messages sent to the user, nil when the user
+// keeps their messages
+func userMessages(p mdb.Prefs, mm *MessageManager) *MessageManager {
+	if !p.AutoDelete {
+		return nil
+	}
+	return mm
+}
+
+func onOff(on bool, lang string) string {
+	if on {
+		return T(lang, "on")
+	}
+	return T(lang, "off")
+}
+
+func modeName(long bool, lang string) string {
+	if long {
+		return T(lang, "mode.long")
+	}
+	return T(lang, "mode.short")
+}
+
+// the settings text and its toggles. the buttons carry the user ID, so only
+// the owner of the settings can press them
+func renderSettings(p mdb.Prefs) (string, tgbotapi.InlineKeyboardMarkup) {
+	lang := p.Language
+	text := T(lang, "settings", subGroupName(p.SubGroup, lang), modeName(p.Long, lang), lang, onOff(p.AutoDelete, lang))
+	data := func(setting string) string {
+		return fmt.Sprintf("set:%d:%v", p.UserID, setting)
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👥 "+subGroupName(p.SubGroup, lang), data("sub")),
+			tgbotapi.NewInlineKeyboardButtonData("📝 "+modeName(p.Long, lang), data("long")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌐 "+p.Language, data("lang")),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 "+T(lang, "settings.autodelete", onOff(p.AutoDelete, lang)), data("del")),
+		),
+	)
+	return text, keyboard
+}
+
+// sends the settings of the user with toggles
+func HandleSettings(db mdb.PrefsStore, chatID int64, userID int64, bot *tgbotapi.BotAPI) {
+	text, keyboard := renderSettings(UserPrefs(db, userID))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+// flips the setting of a pressed toggle and redraws the settings message
+func HandleSettingsCallback(db mdb.PrefsStore, query *tgbotapi.CallbackQuery, bot *tgbotapi.BotAPI) {
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 3 {
+		log.Printf("invalid settings data %q", query.Data)
+		return
+	}
+	userID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || userID != query.From.ID {
+		bot.Request(tgbotapi.NewCallback(query.ID, T(UserPrefs(db, query.From.ID).Language, "settings.foreign")))
+		return
+	}
+	p := UserPrefs(db, userID)
+	switch parts[2] {
+	case "sub":
+		p.SubGroup = nextSubGroup(p.SubGroup)
+	case "long":
+		p.Long = !p.Long
+	case "lang":
+		i := slices.Index(languages, p.Language)
+		p.Language = languages[(i+1)%len(languages)]
+	case "del":
+		p.AutoDelete = !p.AutoDelete
+	default:
+		log.Printf("invalid settings data %q", query.Data)
+		return
+	}
+	if err := db.SavePrefs(p); err != nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	text, keyboard := renderSettings(p)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, keyboard)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := bot.Request(edit); err != nil {
+		log.Printf("error editing message: %v", err)
+	}
+}
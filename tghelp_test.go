@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseArgsWith(t *testing.T) {
+	tests := []struct {
+		str      string
+		defaults string
+		long     bool
+		want     string
+		wantLong bool
+	}{
+		{"", "1", false, "1", false},
+		{"-2", "1", false, "2", false},
+		{"-all -l", "1", false, "", true},
+		{"-s", "2", true, "2", false},
+		{"-l -s", "1", false, "1", false},
+		{"-s -l", "1", false, "1", true},
+		{"-1 unknown", "2", true, "1", true},
+	}
+	for _, tt := range tests {
+		defaults := ParseArgs("")
+		defaults.Group, defaults.Long = tt.defaults, tt.long
+		got := ParseArgsWith(tt.str, defaults)
+		if got.Group != tt.want || got.Long != tt.wantLong {
+			t.Errorf("ParseArgsWith(%q) = subgroup %q long %v, want %q %v", tt.str, got.Group, got.Long, tt.want, tt.wantLong)
+		}
+	}
+}
+
+func TestGetWeekSpan(t *testing.T) {
+	tests := []struct {
+		start   string
+		date    string
+		want    int
+		first   string
+		wantErr bool
+	}{
+		{"2026-08-31", "2026-08-30", 0, "", true},
+		{"2026-08-31", "2026-08-31", 1, "2026-08-31", false},
+		{"2026-08-31", "2026-09-06", 1, "2026-08-31", false},
+		{"2026-08-31", "2026-09-07", 2, "2026-09-07", false},
+		{"2026-08-31", "2026-09-27", 4, "2026-09-21", false},
+		{"2026-08-31", "2026-09-28", 1, "2026-09-28", false},
+		// the clocks go back on the night to 2026-10-25 in europe
+		{"2026-08-31", "2026-10-25", 4, "2026-10-19", false},
+		{"2026-08-31", "2026-10-26", 1, "2026-10-26", false},
+		// weeks of a semester starting on a tuesday run from tuesday to monday
+		{"2026-09-01", "2026-08-31", 0, "", true},
+		{"2026-09-01", "2026-09-01", 1, "2026-09-01", false},
+		{"2026-09-01", "2026-09-07", 1, "2026-09-01", false},
+		{"2026-09-01", "2026-09-08", 2, "2026-09-08", false},
+		{"2026-09-01", "2026-09-28", 4, "2026-09-22", false},
+		{"2026-09-01", "2026-09-29", 1, "2026-09-29", false},
+	}
+	for _, tt := range tests {
+		date, err := time.ParseInLocation("2006-01-02", tt.date, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		week, first, err := GetWeekSpan(tt.start, date.Add(23*time.Hour))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetWeekSpan(%v, %v) error %v, want error %v", tt.start, tt.date, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if week != tt.want || first.Format("2006-01-02") != tt.first || first.Hour() != 0 {
+			t.Errorf("GetWeekSpan(%v, %v) = %d from %v, want %d from %v", tt.start, tt.date, week, first, tt.want, tt.first)
+		}
+	}
+	if _, err := GetWeekAt("01.09.2026", time.Now()); err == nil {
+		t.Error("parsed a start date that is not YYYY-MM-DD")
+	}
+}
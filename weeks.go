@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// the most weeks /weeks sends at once
+const maxWeeks = 2 * mdb.CycleWeeks
+
+// returns the first day of the nearest week, this one or a later one, that
+// is the cycle week
+func CycleWeekStart(week int, now time.Time) (time.Time, error) {
+	current, first, err := GetWeekSpan(os.Getenv("SEMESTER_START_DATE"), now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ahead := (week - current + mdb.CycleWeeks) % mdb.CycleWeeks
+	return first.AddDate(0, 0, 7*ahead), nil
+}
+
+// the first word of the arguments that is not a flag
+func firstWord(args string) string {
+	for _, field := range strings.Fields(args) {
+		if !strings.HasPrefix(field, "-") {
+			return field
+		}
+	}
+	return ""
+}
+
+// handles "/week N", the timetable of the nearest cycle week N
+func SendCycleWeek(db mdb.Store, chatID int64, bot *tgbotapi.BotAPI, opt mdb.Args, args string, mm *MessageManager) {
+	week, err := strconv.Atoi(firstWord(args))
+	if err != nil || week < 1 || week > mdb.CycleWeeks {
+		bot.Send(tgbotapi.NewMessage(chatID, T(opt.Lang, "weeks.week_usage", mdb.CycleWeeks)))
+		return
+	}
+	first, err := CycleWeekStart(week, time.Now())
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	// the week view runs from monday, its middle day picks the calendar
+	// week holding most days of the academic week
+	SendNav(db, chatID, Nav{Date: first.AddDate(0, 0, 3), Week: true, Opt: opt}, bot, mm)
+}
+
+// handles "/weeks [N]", this week and the ones after it, N in all, one
+// message each. without N the whole cycle
+func SendWeeks(db mdb.Store, chatID int64, bot *tgbotapi.BotAPI, opt mdb.Args, args string, mm *MessageManager) {
+	count := mdb.CycleWeeks
+	if word := firstWord(args); word != "" {
+		n, err := strconv.Atoi(word)
+		if err != nil || n < 1 || n > maxWeeks {
+			bot.Send(tgbotapi.NewMessage(chatID, T(opt.Lang, "weeks.usage", maxWeeks)))
+			return
+		}
+		count = n
+	}
+	for i := 0; i < count; i++ {
+		SendWeek(db, chatID, bot, opt, i, mm)
+	}
+}
+
+// handles "/whichweek", the cycle week of today and its dates
+func SendWhichWeek(chatID int64, lang string, bot *tgbotapi.BotAPI) {
+	now := time.Now()
+	week, first, err := GetWeekSpan(os.Getenv("SEMESTER_START_DATE"), now)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error : %v", err)))
+		return
+	}
+	last := first.AddDate(0, 0, 6)
+	next := week%mdb.CycleWeeks + 1
+	text := T(lang, "weeks.which", week, mdb.CycleWeeks, first.Format("02.01"), last.Format("02.01"), next)
+	bot.Send(tgbotapi.NewMessage(chatID, text))
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RemyJohnny/timetable/mdb"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// choices over the keys of a map, sorted by their numeric value
+func keyChoices(m map[string]int) []Choice {
+	keys := slices.SortedFunc(maps.Keys(m), func(a, b string) int {
+		return cmp.Or(cmp.Compare(m[a], m[b]), cmp.Compare(a, b))
+	})
+	choices := make([]Choice, len(keys))
+	for i, key := range keys {
+		choices[i] = Choice{Label: key, Value: key}
+	}
+	return choices
+}
+
+func weekChoices(ctx FormContext) []Choice {
+	return keyChoices(mdb.Weeks)
+}
+
+func subGroupChoices(ctx FormContext) []Choice {
+	return keyChoices(mdb.SubGroup)
+}
+
+func typeChoices(ctx FormContext) []Choice {
+	return keyChoices(ctx.Cat.Types)
+}
+
+func subjectChoices(ctx FormContext) []Choice {
+	var choices []Choice
+	for _, subject := range ctx.Cat.Subjects {
+		choices = append(choices, Choice{Label: subject.Name, Value: subject.Key})
+	}
+	slices.SortFunc(choices, func(a, b Choice) int { return cmp.Compare(a.Label, b.Label) })
+	return choices
+}
+
+func dayChoices(ctx FormContext) []Choice {
+	var choices []Choice
+	for _, day := range slices.Sorted(maps.Keys(ctx.Cat.Days)) {
+		choices = append(choices, Choice{Label: ctx.Cat.Days[day], Value: strconv.Itoa(day)})
+	}
+	return choices
+}
+
+func periodChoices(ctx FormContext) []Choice {
+	var choices []Choice
+	for _, number := range slices.Sorted(maps.Keys(ctx.Cat.Periods)) {
+		choices = append(choices, Choice{Label: ctx.Cat.Periods[number].String(), Value: strconv.Itoa(number)})
+	}
+	return choices
+}
+
+// the fields of a lecture, shared by the add and edit wizards. skip marks
+// them as optional for editing, where the session starts with the old
+// lecture
+func lectureFields(skip bool) []Field {
+	return []Field{
+		{
+			Prompt:  "wizard.week",
+			Choices: weekChoices,
+			Skip:    skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				if _, ok := mdb.Weeks[value]; !ok {
+					return errors.New(T(ctx.Lang, "wizard.week.invalid"))
+				}
+				s.NewLecture.Week = value
+				return nil
+			},
+		},
+		{
+			Prompt:  "wizard.subject",
+			Choices: subjectChoices,
+			Skip:    skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				subject, ok := ctx.Cat.Subjects[value]
+				if !ok {
+					return errors.New(T(ctx.Lang, "wizard.subject.invalid"))
+				}
+				s.NewLecture.Subject = subject.Key
+				s.NewLecture.Lecturer = subject.Lecturer
+				return nil
+			},
+		},
+		{
+			Prompt:  "wizard.type",
+			Choices: typeChoices,
+			Skip:    skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				if _, ok := ctx.Cat.Types[value]; !ok {
+					return errors.New(T(ctx.Lang, "wizard.type.invalid"))
+				}
+				s.NewLecture.Type = value
+				return nil
+			},
+		},
+		{
+			Prompt:  "wizard.day",
+			Choices: dayChoices,
+			Skip:    skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				day, err := strconv.Atoi(value)
+				if _, ok := ctx.Cat.Days[day]; err != nil || !ok {
+					return errors.New(T(ctx.Lang, "wizard.day.invalid"))
+				}
+				s.NewLecture.Day = day
+				return nil
+			},
+		},
+		{
+			Prompt: "wizard.room",
+			Skip:   skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				if value == "" {
+					return errors.New(T(ctx.Lang, "wizard.room.empty"))
+				}
+				s.NewLecture.Room = value
+				return nil
+			},
+		},
+		{
+			Prompt:  "wizard.period",
+			Choices: periodChoices,
+			Skip:    skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				number, err := strconv.Atoi(value)
+				if _, ok := ctx.Cat.Periods[number]; err != nil || !ok {
+					return errors.New(T(ctx.Lang, "wizard.period.invalid"))
+				}
+				s.NewLecture.Time = number
+				return nil
+			},
+		},
+		{
+			Prompt:  "wizard.subgroup",
+			Choices: subGroupChoices,
+			Skip:    skip,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				if _, ok := mdb.SubGroup[value]; !ok {
+					return errors.New(T(ctx.Lang, "wizard.subgroup.invalid"))
+				}
+				s.NewLecture.SubGroup = value
+				return nil
+			},
+		},
+	}
+}
+
+// new lectures are for the whole group unless a subgroup is picked
+func addLectureFields() []Field {
+	fields := lectureFields(false)
+	fields[len(fields)-1].Default = "0"
+	return append(fields, conflictField)
+}
+
+// warns about the stored lectures the entered one clashes with, only asked
+// when there are any. the admin may save the lecture anyway
+var conflictField = Field{
+	Prompt:  "wizard.force",
+	Details: lectureConflicts,
+	Ask: func(ctx FormContext, s mdb.Session) bool {
+		return mdb.ValidateLecture(ctx.DB, s.NewLecture) != nil
+	},
+	Choices: yesChoice,
+	Set: func(ctx FormContext, s *mdb.Session, value string) error {
+		if value != "yes" {
+			return errors.New(T(ctx.Lang, "wizard.force.invalid"))
+		}
+		s.Force = true
+		return nil
+	},
+}
+
+// the stored lectures the entered one clashes with and why
+func lectureConflicts(ctx FormContext, s mdb.Session) string {
+	conflicts, err := mdb.FindConflicts(ctx.DB, s.NewLecture)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	text := T(ctx.Lang, "wizard.conflicts")
+	for _, c := range conflicts {
+		text += fmt.Sprintf("\n[ %v ] %v: %v", c.Lecture.ID.Hex(), lectureLine(c.Lecture, ctx.Cat, ctx.Lang), conflictReasons(c, ctx.Lang))
+	}
+	return text
+}
+
+// the error for the chat, a *mdb.ConflictError lists its clashes in the
+// language
+func errorText(err error, lang string) string {
+	var conflict *mdb.ConflictError
+	if !errors.As(err, &conflict) {
+		return fmt.Sprintf("error: %v", err)
+	}
+	text := T(lang, "wizard.conflicts")
+	for _, c := range conflict.Conflicts {
+		text += "\n" + conflictLine(c, lang)
+	}
+	return text
+}
+
+// why the lectures clash
+func conflictReasons(c mdb.Conflict, lang string) string {
+	var reasons []string
+	if c.Overlap {
+		reasons = append(reasons, T(lang, "wizard.conflict.overlap"))
+	}
+	if c.Room {
+		reasons = append(reasons, T(lang, "wizard.conflict.room", c.Lecture.Room))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// describes the clash with a lecture that may be of another group, so
+// without its catalog
+func conflictLine(c mdb.Conflict, lang string) string {
+	l := c.Lecture
+	id := l.ID.Hex()
+	if c.Pending {
+		id = T(lang, "conflict.pending", c.Index+1)
+	}
+	return T(lang, "conflict.line", id, l.Subject, l.Week, l.Day, l.Time, l.SubGroup, l.Group, conflictReasons(c, lang))
+}
+
+// the button confirming a question
+func yesChoice(ctx FormContext) []Choice {
+	return []Choice{{Label: T(ctx.Lang, "form.yes"), Value: "yes"}}
+}
+
+// edits start with the ID of the lecture, its fields then show what is
+// being changed
+func editLectureFields() []Field {
+	fields := lectureFields(true)
+	fields[0].Details = sessionLecture
+	fields = append(fields, conflictField)
+	return append([]Field{lectureIDField("wizard.edit_id")}, fields...)
+}
+
+// asks for the ID of a lecture of the chat group and loads it into the
+// session. prompt is the key of the question
+func lectureIDField(prompt string) Field {
+	return Field{
+		Prompt: prompt,
+		Set: func(ctx FormContext, s *mdb.Session, value string) error {
+			if _, err := primitive.ObjectIDFromHex(value); err != nil {
+				return errors.New(T(ctx.Lang, "wizard.invalid_id"))
+			}
+			l, err := ctx.DB.GetLecture(value)
+			if err != nil {
+				return err
+			}
+			if l.Group != ctx.Group {
+				return errors.New(T(ctx.Lang, "wizard.other_group", value))
+			}
+			s.OldLecture = l
+			s.NewLecture = l
+			return nil
+		},
+	}
+}
+
+var addLectureForm = Form{
+	Fields:    addLectureFields(),
+	Cancelled: "wizard.add.cancelled",
+	Submit: func(ctx FormContext, s mdb.Session) (string, error) {
+		if err := mdb.InsertLectureChecked(ctx.DB, s.NewLecture, s.Force); err != nil {
+			return "", err
+		}
+		log.Printf("New lecture : %+v", s.NewLecture)
+		return T(ctx.Lang, "wizard.added"), nil
+	},
+}
+
+var editLectureForm = Form{
+	Fields:    editLectureFields(),
+	Cancelled: "wizard.edit.cancelled",
+	Submit: func(ctx FormContext, s mdb.Session) (string, error) {
+		if err := mdb.UpdateLectureChecked(ctx.DB, s.OldLecture.ID, s.NewLecture, s.Force); err != nil {
+			return "", err
+		}
+		log.Printf("updated lecture : %v", s.OldLecture.ID.Hex())
+		NotifyLectureUpdate(ctx.DB, s.OldLecture, s.NewLecture, ctx.Bot)
+		return T(ctx.Lang, "wizard.updated", s.OldLecture.ID.Hex()), nil
+	},
+}
+
+// the lecture loaded into the session, for the fields working on it
+func sessionLecture(ctx FormContext, s mdb.Session) string {
+	return fmt.Sprintf("[ %v ] %v", s.OldLecture.ID.Hex(), lectureLine(s.OldLecture, ctx.Cat, ctx.Lang))
+}
+
+var deleteLectureForm = Form{
+	Fields: []Field{
+		lectureIDField("wizard.delete_id"),
+		{
+			Prompt:  "wizard.delete",
+			Details: sessionLecture,
+			Choices: yesChoice,
+			Set: func(ctx FormContext, s *mdb.Session, value string) error {
+				if value != "yes" {
+					return errors.New(T(ctx.Lang, "wizard.delete.invalid"))
+				}
+				return nil
+			},
+		},
+	},
+	Cancelled: "wizard.delete.cancelled",
+	Submit: func(ctx FormContext, s mdb.Session) (string, error) {
+		id := s.OldLecture.ID.Hex()
+		if err := ctx.DB.DeleteLecture(id); err != nil {
+			return "", err
+		}
+		NotifyLectureDelete(ctx.DB, s.OldLecture, ctx.Bot)
+		return T(ctx.Lang, "wizard.deleted", id), nil
+	},
+}
+
+// starts the lecture wizard of the kind for the user in the chat
+func StartWizard(db mdb.Store, sessions *Sessions, group string, kind string, chatID, userID int64, lang string, bot *tgbotapi.BotAPI) {
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	session := mdb.Session{ChatID: chatID, UserID: userID, Kind: kind}
+	session.NewLecture.Group = group
+	StartForm(FormContext{DB: db, Group: group, Cat: cat, Bot: bot, Lang: lang}, sessions, session, chatID)
+}
+
+// starts the edit or delete wizard on a lecture picked from /lectures, past
+// the question for its ID
+func StartLectureWizard(db mdb.Store, sessions *Sessions, group string, kind string, lecture mdb.Lecture, chatID, userID int64, lang string, bot *tgbotapi.BotAPI) {
+	if lecture.Group != group {
+		bot.Send(tgbotapi.NewMessage(chatID, T(lang, "wizard.other_group", lecture.ID.Hex())))
+		return
+	}
+	cat, err := mdb.GetCatalog(db, group)
+	if err != nil {
+		bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("error: %v", err)))
+		return
+	}
+	session := mdb.Session{ChatID: chatID, UserID: userID, Kind: kind, Step: 1, OldLecture: lecture, NewLecture: lecture}
+	StartForm(FormContext{DB: db, Group: group, Cat: cat, Bot: bot, Lang: lang}, sessions, session, chatID)
+}